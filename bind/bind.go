@@ -2,6 +2,7 @@ package bind
 
 import (
 	"fmt"
+	"strings"
 )
 
 type Hint string
@@ -12,6 +13,9 @@ var (
 	HintGet    Hint = "get"
 	HintSet    Hint = "set"
 	HintExport Hint = "export"
+	// HintBind is used on interfaces, each method is a HintFunc, HintGet
+	// or HintSet of the target, implemented by a generated type.
+	HintBind Hint = "bind"
 )
 
 type ArgumentMode uint32
@@ -21,11 +25,24 @@ const (
 	ModePointer
 	ModeArray
 	ModeSlice
+	ModeChan
+	ModeMap
 )
 
 type BridgeFuncInfo struct {
 	JS   string
 	Size int
+	// Align is the alignment, if it's not the Size, such as exported
+	// structs.
+	Align int
+}
+
+// Alignment returns the Align, or the Size if Align isn't defined.
+func (b BridgeFuncInfo) Alignment() int {
+	if b.Align > 0 {
+		return b.Align
+	}
+	return b.Size
 }
 
 var BridgeFunc = map[ArgumentMode]map[string]BridgeFuncInfo{
@@ -50,23 +67,37 @@ var BridgeFunc = map[ArgumentMode]map[string]BridgeFuncInfo{
 		"rune":           {JS: "globalThis.inkwasm.Load.Rune", Size: 8},
 		"unsafe.pointer": {JS: "globalThis.inkwasm.Load.UnsafePointer", Size: 8},
 		"inkwasm.object": {JS: "globalThis.inkwasm.Load.InkwasmObject", Size: 16},
+		"big.int":        {JS: "globalThis.inkwasm.Load.BigInt", Size: 32},
+		"complex64":      {JS: "globalThis.inkwasm.Load.Complex64", Size: 8},
+		"complex128":     {JS: "globalThis.inkwasm.Load.Complex128", Size: 16},
 	},
 	ModeArray: {
-		"default":     {JS: "globalThis.inkwasm.Load.Array", Size: -1},
-		"interface{}": {JS: "globalThis.inkwasm.Load.ArrayInterface", Size: 16},
-		"float32":     {JS: "globalThis.inkwasm.Load.ArrayFloat32", Size: 4},
-		"float64":     {JS: "globalThis.inkwasm.Load.ArrayFloat64", Size: 8},
-		"uintptr":     {JS: "globalThis.inkwasm.Load.ArrayUintPtr", Size: 8},
-		"byte":        {JS: "globalThis.inkwasm.Load.ArrayByte", Size: 1},
-		"uint8":       {JS: "globalThis.inkwasm.Load.ArrayUint8", Size: 1},
-		"uint16":      {JS: "globalThis.inkwasm.Load.ArrayUint16", Size: 2},
-		"uint32":      {JS: "globalThis.inkwasm.Load.ArrayUint32", Size: 4},
-		"uint64":      {JS: "globalThis.inkwasm.Load.ArrayUint64", Size: 8},
-		"int8":        {JS: "globalThis.inkwasm.Load.ArrayInt8", Size: 1},
-		"int16":       {JS: "globalThis.inkwasm.Load.ArrayInt16", Size: 2},
-		"int32":       {JS: "globalThis.inkwasm.Load.ArrayInt32", Size: 4},
-		"int64":       {JS: "globalThis.inkwasm.Load.ArrayInt64", Size: 8},
-		"rune":        {JS: "globalThis.inkwasm.Load.ArrayRune", Size: 8},
+		"default":        {JS: "globalThis.inkwasm.Load.Array", Size: -1},
+		"interface{}":    {JS: "globalThis.inkwasm.Load.ArrayInterface", Size: 16},
+		"float32":        {JS: "globalThis.inkwasm.Load.ArrayFloat32", Size: 4},
+		"float64":        {JS: "globalThis.inkwasm.Load.ArrayFloat64", Size: 8},
+		"uintptr":        {JS: "globalThis.inkwasm.Load.ArrayUintPtr", Size: 8},
+		"byte":           {JS: "globalThis.inkwasm.Load.ArrayByte", Size: 1},
+		"uint8":          {JS: "globalThis.inkwasm.Load.ArrayUint8", Size: 1},
+		"uint16":         {JS: "globalThis.inkwasm.Load.ArrayUint16", Size: 2},
+		"uint32":         {JS: "globalThis.inkwasm.Load.ArrayUint32", Size: 4},
+		"uint64":         {JS: "globalThis.inkwasm.Load.ArrayUint64", Size: 8},
+		"int8":           {JS: "globalThis.inkwasm.Load.ArrayInt8", Size: 1},
+		"int16":          {JS: "globalThis.inkwasm.Load.ArrayInt16", Size: 2},
+		"int32":          {JS: "globalThis.inkwasm.Load.ArrayInt32", Size: 4},
+		"int64":          {JS: "globalThis.inkwasm.Load.ArrayInt64", Size: 8},
+		"rune":           {JS: "globalThis.inkwasm.Load.ArrayRune", Size: 8},
+		"bool":           {JS: "globalThis.inkwasm.Load.ArrayBool", Size: 1},
+		"string":         {JS: "globalThis.inkwasm.Load.ArrayString", Size: 16},
+		"inkwasm.object": {JS: "globalThis.inkwasm.Load.ArrayInkwasmObject", Size: 16},
+		"int":            {JS: "globalThis.inkwasm.Load.ArrayInt", Size: 8},
+		"uint":           {JS: "globalThis.inkwasm.Load.ArrayUint", Size: 8},
+		"complex64":      {JS: "globalThis.inkwasm.Load.ArrayComplex64", Size: 8},
+		"complex128":     {JS: "globalThis.inkwasm.Load.ArrayComplex128", Size: 16},
+
+		// Interleaved real and imaginary parts, see OptionComplex.
+		"complex64/interleaved":  {JS: "globalThis.inkwasm.Load.ArrayComplex64Interleaved", Size: 8},
+		"complex128/interleaved": {JS: "globalThis.inkwasm.Load.ArrayComplex128Interleaved", Size: 16},
 	},
 	ModeSlice: {
 		"default": {JS: "globalThis.inkwasm.Load.Slice", Size: 24},
@@ -74,6 +105,28 @@ var BridgeFunc = map[ArgumentMode]map[string]BridgeFuncInfo{
 	ModePointer: {
 		"default": {JS: "globalThis.inkwasm.Load.Ptr", Size: 8},
 	},
+	ModeChan: {
+		// The channel is an async iterable, see inkwasm.SendChan.
+		"inkwasm.object": {JS: "globalThis.inkwasm.Load.InkwasmObject", Size: 16},
+		"string":         {JS: "globalThis.inkwasm.Load.InkwasmObject", Size: 16},
+		"bool":           {JS: "globalThis.inkwasm.Load.InkwasmObject", Size: 16},
+		"byte":           {JS: "globalThis.inkwasm.Load.InkwasmObject", Size: 16},
+		"int":            {JS: "globalThis.inkwasm.Load.InkwasmObject", Size: 16},
+		"int8":           {JS: "globalThis.inkwasm.Load.InkwasmObject", Size: 16},
+		"int16":          {JS: "globalThis.inkwasm.Load.InkwasmObject", Size: 16},
+		"int32":          {JS: "globalThis.inkwasm.Load.InkwasmObject", Size: 16},
+		"uint":           {JS: "globalThis.inkwasm.Load.InkwasmObject", Size: 16},
+		"uint8":          {JS: "globalThis.inkwasm.Load.InkwasmObject", Size: 16},
+		"uint16":         {JS: "globalThis.inkwasm.Load.InkwasmObject", Size: 16},
+		"uint32":         {JS: "globalThis.inkwasm.Load.InkwasmObject", Size: 16},
+		"float32":        {JS: "globalThis.inkwasm.Load.InkwasmObject", Size: 16},
+		"float64":        {JS: "globalThis.inkwasm.Load.InkwasmObject", Size: 16},
+	},
+	ModeMap: {
+		// The keys and values are slices, see inkwasm.MapEntries.
+		"default": {JS: "globalThis.inkwasm.Load.MapObject", Size: 48},
+		"map":     {JS: "globalThis.inkwasm.Load.Map", Size: 48},
+	},
 }
 
 var ResultFunc = map[ArgumentMode]map[string]BridgeFuncInfo{
@@ -95,9 +148,11 @@ var ResultFunc = map[ArgumentMode]map[string]BridgeFuncInfo{
 		"int64":          {JS: "globalThis.inkwasm.Set.Int64", Size: 8},
 		"string":         {JS: "globalThis.inkwasm.Set.String", Size: 16},
 		"rune":           {JS: "globalThis.inkwasm.Set.Rune", Size: 8},
-		"big.int":        {JS: "globalThis.inkwasm.Set.BigInt", Size: 32},
+		"big.int":        {JS: "globalThis.inkwasm.Set.BigInt", Size: 16}, // Size is 16 because it's Object
 		"unsafe.pointer": {JS: "globalThis.inkwasm.Set.UnsafePointer", Size: 8},
 		"inkwasm.object": {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"complex64":      {JS: "globalThis.inkwasm.Set.Complex64", Size: 8},
+		"complex128":     {JS: "globalThis.inkwasm.Set.Complex128", Size: 16},
 	},
 	ModeArray: {
 		"default": {JS: "globalThis.inkwasm.Set.Array", Size: -1},
@@ -116,11 +171,60 @@ var ResultFunc = map[ArgumentMode]map[string]BridgeFuncInfo{
 		"int16":   {JS: "globalThis.inkwasm.Set.Int16", Size: 2},
 		"int32":   {JS: "globalThis.inkwasm.Set.Int32", Size: 4},
 		"int64":   {JS: "globalThis.inkwasm.Set.Int64", Size: 8},
+
+		"complex64":  {JS: "globalThis.inkwasm.Set.Complex64", Size: 8},
+		"complex128": {JS: "globalThis.inkwasm.Set.Complex128", Size: 16},
 	},
 	ModeSlice: {
 		"default": {JS: "globalThis.inkwasm.Set.Slice", Size: 16}, // Size is 16 because it's Object
+
+		// Complex numbers are interleaved, if they aren't already.
+		"complex64":  {JS: "globalThis.inkwasm.Set.SliceComplex64", Size: 16},
+		"complex128": {JS: "globalThis.inkwasm.Set.SliceComplex128", Size: 16},
+	},
+	ModePointer: {
+		"big.int": {JS: "globalThis.inkwasm.Set.BigInt", Size: 16}, // Size is 16 because it's Object
+
+		// Results are nil if null or undefined, see inkwasm.DecodePtr.
+		"inkwasm.object": {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"string":         {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"bool":           {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"byte":           {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"int":            {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"int8":           {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"int16":          {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"int32":          {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"uint":           {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"uint8":          {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"uint16":         {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"uint32":         {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"float32":        {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"float64":        {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+
+		// Writes into the pointer argument, see OptionOut and OptionInOut.
+		"default": {JS: "globalThis.inkwasm.Set.Ptr", Size: 8},
+	},
+	ModeChan: {
+		// The channel receives from the iterable, see inkwasm.ReceiveChan.
+		"inkwasm.object": {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"string":         {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"bool":           {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"byte":           {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"int":            {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"int8":           {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"int16":          {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"int32":          {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"uint":           {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"uint8":          {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"uint16":         {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"uint32":         {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"float32":        {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+		"float64":        {JS: "globalThis.inkwasm.Set.InkwasmObject", Size: 16},
+	},
+	ModeMap: {
+		// The keys and values are decoded as ModeChan, see inkwasm.DecodeMap.
+		"default": {JS: "globalThis.inkwasm.Set.Map", Size: 16}, // Size is 16 because it's Object
 	},
-	ModePointer: {},
 }
 
 type Argument struct {
@@ -130,6 +234,34 @@ type Argument struct {
 	Type    string
 	SubType *Argument
 	Len     uint64 // Len for Array
+
+	// Omit, OmitEmpty and Inline are the options of the js tag of the
+	// fields of exported structs, such as `js:"name,omitempty"`, `js:"-"`
+	// and `js:",inline"`.
+	Omit      bool
+	OmitEmpty bool
+	Inline    bool
+
+	// Offset is the offset of the field of exported structs, see
+	// FunctionGolang.Size.
+	Offset int64
+
+	// Key is the key of the ModeMap, the SubType is the value.
+	Key *Argument
+
+	// Dir is the direction of the ModeChan, such as "<-chan", "chan<-"
+	// or "chan".
+	Dir string
+
+	// Wrapper is the named type of inkwasm.Object, such as "Canvas" for
+	// "type Canvas inkwasm.Object", the Type is "inkwasm.Object". Embedded
+	// is true if it's a struct which embeds the inkwasm.Object instead.
+	Wrapper  string
+	Embedded bool
+
+	// Variadic is true for the last argument declared as ...T, which
+	// is a ModeSlice spread into the arguments of the JS function.
+	Variadic bool
 }
 
 type Package struct {
@@ -141,22 +273,178 @@ type Package struct {
 type Function struct {
 	File   string
 	Line   int
+	Column int
 	IsTest bool
 	FunctionGolang
 	FunctionJavascript
 }
 
 type FunctionJavascript struct {
-	Name string
-	Hint Hint
+	Name    string
+	Hint    Hint
+	Options Options
+}
+
+// Options are the options of the directive, given after the name, such
+// as "//inkwasm:func .getContext catch=silent nullable". Options without
+// value, such as "nullable", have an empty value.
+type Options map[string]string
+
+var (
+	// OptionCatch controls the exceptions: "log" (default) logs it,
+	// "silent" ignores it and "throw" doesn't catch it. The exceptions
+	// are only caught if there's no result, or the last result is bool.
+	OptionCatch = "catch"
+	// OptionCopy with "args" gives copies of the slices, arrays and
+	// pointers, instead of views of the Go memory, so the JS function
+	// can keep them.
+	OptionCopy = "copy"
+	// OptionNullable makes the last result, which must be bool, false
+	// when the value is null or undefined. Exceptions aren't caught,
+	// unless OptionCatch is given.
+	OptionNullable = "nullable"
+	// OptionComplex controls the arrays and slices of complex numbers:
+	// "object" (default) uses arrays of {re, im}, and "interleaved" uses
+	// Float32Array or Float64Array of the real and imaginary parts.
+	OptionComplex = "complex"
+	// OptionMap controls the map arguments: "object" (default) uses
+	// plain JS objects, and "map" uses Map.
+	OptionMap = "map"
+	// OptionOut names the pointer argument which receives the JS result,
+	// instead of being given to the JS function.
+	OptionOut = "out"
+	// OptionInOut names the pointer arguments, separated by comma, which
+	// are given to the JS function and written back after the call.
+	OptionInOut = "inout"
+)
+
+// Has reports whether the option is present.
+func (o Options) Has(name string) bool {
+	_, ok := o[name]
+	return ok
+}
+
+// List returns the values of the option separated by comma, such as
+// "inout=a,b". It's nil if the option isn't present.
+func (o Options) List(name string) []string {
+	if o[name] == "" {
+		return nil
+	}
+	return strings.Split(o[name], ",")
 }
 
 type FunctionGolang struct {
-	Name      string
+	Name string
+	// Receiver is the receiver of methods, it's always a Wrapper of
+	// inkwasm.Object, which is the JS this.
+	Receiver  *Argument
 	Arguments []Argument
 	Result    []Argument
+
+	// Interface is the interface implemented by Type, the methods are
+	// generated by HintBind, instead of declared.
+	Interface string
+	Type      string
+
+	// Size and Align are the size and alignment of exported structs,
+	// using GOARCH=wasm. The Size is zero if unknown, then the offsets
+	// of the fields are computed from the sizes of each field.
+	Size  int64
+	Align int64
+}
+
+// Symbol returns the name used by the generated functions, which is
+// "Type_Method" for methods.
+func (f FunctionGolang) Symbol() string {
+	if f.Receiver != nil {
+		return f.Receiver.Wrapper + "_" + f.Name
+	}
+	if f.Type != "" {
+		return f.Type + "_" + f.Name
+	}
+	return f.Name
+}
+
+func (f *Function) CreateError(code Code, format string, a ...interface{}) error {
+	return Diagnostic{
+		File:     f.File,
+		Line:     f.Line,
+		Column:   f.Column,
+		Severity: SeverityError,
+		Code:     code,
+		Message:  fmt.Sprintf(format, a...),
+	}
+}
+
+type Severity string
+
+var (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Code identifies the kind of Diagnostic. Codes are stable, and can be
+// used by editors and CI to filter or document the problems.
+type Code string
+
+var (
+	CodeUnknownHint      Code = "unknown-hint"
+	CodeUnsupportedType  Code = "unsupported-type"
+	CodeMissingReceiver  Code = "missing-receiver"
+	CodeInvalidArguments Code = "invalid-arguments"
+	CodeInvalidResults   Code = "invalid-results"
+	CodeInvalidStruct    Code = "invalid-struct"
+	CodeDirectiveBody    Code = "directive-body"
+	CodeInvalidOption    Code = "invalid-option"
+	CodeInvalidTemplate  Code = "invalid-template"
+	CodeInvalidReceiver  Code = "invalid-receiver"
+	CodeInvalidBind      Code = "invalid-bind"
+)
+
+// Diagnostic is one problem found in a directive, or in the declaration
+// which follows it.
+type Diagnostic struct {
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Column   int      `json:"column"`
+	Severity Severity `json:"severity"`
+	Code     Code     `json:"code"`
+	Message  string   `json:"message"`
+}
+
+func (d Diagnostic) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s [%s]", d.File, d.Line, d.Column, d.Severity, d.Message, d.Code)
+}
+
+// Diagnostics is a list of Diagnostic, which is also an error.
+type Diagnostics []Diagnostic
+
+func (d Diagnostics) Error() string {
+	s := make([]string, len(d))
+	for i := range d {
+		s[i] = d[i].Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+// Append appends err to the list, err is flattened if it's also
+// Diagnostics. Other errors are appended without position.
+func (d *Diagnostics) Append(err error) {
+	switch err := err.(type) {
+	case nil:
+	case Diagnostics:
+		*d = append(*d, err...)
+	case Diagnostic:
+		*d = append(*d, err)
+	default:
+		*d = append(*d, Diagnostic{Severity: SeverityError, Message: err.Error()})
+	}
 }
 
-func (f *Function) CreateError(format string, a ...interface{}) error {
-	return fmt.Errorf("%s:%d: %s", f.File, f.Line, fmt.Sprintf(format, a...))
+// Err returns nil if the list is empty, or the list otherwise.
+func (d Diagnostics) Err() error {
+	if len(d) == 0 {
+		return nil
+	}
+	return d
 }
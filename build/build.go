@@ -1,6 +1,10 @@
 package build
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"golang.org/x/tools/go/packages"
 	"io"
@@ -8,6 +12,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 )
 
@@ -19,6 +24,21 @@ type BuilderConfig struct {
 	Ldflags     string
 	IncludeTest bool
 	GCFlags     string
+	// Template is the path of the index.html, it replaces the index.html
+	// on each build. If empty, the index.html is only created if missing.
+	Template string
+
+	// Overlay controls the error overlay shown by the loader when
+	// main.wasm fails to load or the program exits with non-zero code.
+	// Empty uses the built-in overlay, "none" disables it and any other
+	// value is the id of an element, in index.html, to be used instead.
+	Overlay string
+	// CrashReport is the endpoint that receives a POST, with the
+	// panic/exit message, when the program crashes. Empty disables it.
+	CrashReport string
+	// SingleFile also creates a self-contained main.html, with main.wasm,
+	// wasm.js and index.html inlined, which works from file://.
+	SingleFile bool
 }
 
 type Builder struct {
@@ -69,23 +89,140 @@ func (b *Builder) Build() error {
 		fmt.Println(string(r))
 	}
 
-	return b.BuildFiles()
+	if err := b.BuildFiles(); err != nil {
+		return err
+	}
+
+	if b.config.SingleFile {
+		return b.BuildSingleFile()
+	}
+
+	return nil
 }
 
 func (b *Builder) BuildFiles() error {
-	if _, err := os.Stat(filepath.Join(b.config.Output, "index.html")); err != nil {
+	if b.config.Template != "" {
+		index, err := ioutil.ReadFile(b.config.Template)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(b.config.Output, "index.html"), index, 0600); err != nil {
+			return err
+		}
+	} else if _, err := os.Stat(filepath.Join(b.config.Output, "index.html")); err != nil {
 		if err := ioutil.WriteFile(filepath.Join(b.config.Output, "index.html"), []byte(jsIndex), 0600); err != nil {
 			return err
 		}
 	}
 
-	goroot, err := exec.Command("go", "env", "GOROOT").Output()
+	files, err := b.jsFiles()
+	if err != nil {
+		return err
+	}
+
+	return mergeJSFiles(filepath.Join(b.config.Output, "wasm.js"), b.loaderConfig(), files...)
+}
+
+// BuildSingleFile creates the main.html, based on the index.html, with
+// the main.wasm and all Javascript files embedded into it. The main.wasm
+// is compressed with gzip and decompressed using DecompressionStream.
+func (b *Builder) BuildSingleFile() error {
+	index, err := ioutil.ReadFile(filepath.Join(b.config.Output, "index.html"))
+	if err != nil {
+		return err
+	}
+
+	wasm, err := ioutil.ReadFile(filepath.Join(b.config.Output, "main.wasm"))
+	if err != nil {
+		return err
+	}
+
+	compressed := bytes.NewBuffer(nil)
+	gz, err := gzip.NewWriterLevel(compressed, gzip.BestCompression)
 	if err != nil {
 		return err
 	}
+	if _, err := gz.Write(wasm); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+
+	files, err := b.jsFiles()
+	if err != nil {
+		return err
+	}
+
+	loader := b.loaderConfig()
+	loader.Embedded = base64.StdEncoding.EncodeToString(compressed.Bytes())
+
+	js := bytes.NewBuffer(nil)
+	if err := writeJSFiles(js, loader, files...); err != nil {
+		return err
+	}
+
+	// Inline scripts can't be deferred, unless it's a module.
+	script := []byte(`<script type="module">` + strings.ReplaceAll(js.String(), "</script", `<\/script`) + `</script>`)
+	if loc := jsScriptTag.FindIndex(index); loc != nil {
+		index = append(index[:loc[0]:loc[0]], append(script, index[loc[1]:]...)...)
+	} else if i := bytes.LastIndex(index, []byte("</body>")); i >= 0 {
+		index = append(index[:i:i], append(script, index[i:]...)...)
+	} else {
+		index = append(index, script...)
+	}
+
+	return ioutil.WriteFile(filepath.Join(b.config.Output, "main.html"), index, 0600)
+}
+
+// jsScriptTag matches the <script> which loads the wasm.js.
+var jsScriptTag = regexp.MustCompile(`<script[^>]*\ssrc=["']?wasm\.js["']?[^>]*>\s*</script>`)
+
+// Clean removes the files created by Build from the Output folder, and
+// the folder itself if it becomes empty. The index.html is only removed
+// if it's created from the Template or it's the default one, since it
+// may be edited otherwise. It returns the removed paths.
+func (b *Builder) Clean(dryRun bool) ([]string, error) {
+	var removed []string
+	for _, name := range []string{"main.wasm", "wasm.js", "main.html", "index.html"} {
+		path := filepath.Join(b.config.Output, name)
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return removed, err
+		}
+		if name == "index.html" && b.config.Template == "" && string(content) != jsIndex {
+			continue
+		}
+		if !dryRun {
+			if err := os.Remove(path); err != nil {
+				return removed, err
+			}
+		}
+		removed = append(removed, path)
+	}
+
+	if dir, err := os.ReadDir(b.config.Output); err == nil && len(dir) == 0 && !dryRun {
+		if err := os.Remove(b.config.Output); err != nil {
+			return removed, err
+		}
+	}
+
+	return removed, nil
+}
+
+// jsFiles returns all Javascript files that must be merged into the
+// wasm.js, starting with the wasm_exec.js.
+func (b *Builder) jsFiles() ([]string, error) {
+	goroot, err := exec.Command("go", "env", "GOROOT").Output()
+	if err != nil {
+		return nil, err
+	}
 	wasmJS := filepath.Join(strings.TrimSpace(string(goroot)), "misc", "wasm", "wasm_exec.js")
 	if _, err := os.Stat(wasmJS); err != nil {
-		return fmt.Errorf("failed to find $GOROOT/misc/wasm/wasm_exec.js driver: %v", err)
+		return nil, fmt.Errorf("failed to find $GOROOT/misc/wasm/wasm_exec.js driver: %v", err)
 	}
 	pkgs, err := packages.Load(&packages.Config{
 		Mode:  packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps,
@@ -93,17 +230,38 @@ func (b *Builder) BuildFiles() error {
 		Tests: b.config.IncludeTest,
 	}, b.config.Source)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if len(pkgs) > 1 && len(pkgs[0].GoFiles) == 0 {
 		pkgs[0] = pkgs[1]
 	}
 	extraJS, err := b.findPackagesJS(pkgs[0], make(map[string]bool))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return mergeJSFiles(filepath.Join(b.config.Output, "wasm.js"), append([]string{wasmJS}, extraJS...)...)
+	return append([]string{wasmJS}, extraJS...), nil
+}
+
+// loaderConfig is the configuration used by jsStartGo, it can be
+// overridden in the page by setting `globalThis.InkwasmLoader`.
+type loaderConfig struct {
+	WASM        string      `json:"wasm"`
+	Overlay     interface{} `json:"overlay"`
+	CrashReport string      `json:"crashReport,omitempty"`
+	Embedded    string      `json:"embedded,omitempty"` // gzip+base64 of main.wasm
+}
+
+func (b *Builder) loaderConfig() loaderConfig {
+	cfg := loaderConfig{WASM: "main.wasm", Overlay: true, CrashReport: b.config.CrashReport}
+	switch b.config.Overlay {
+	case "":
+	case "none":
+		cfg.Overlay = false
+	default:
+		cfg.Overlay = b.config.Overlay
+	}
+	return cfg
 }
 
 func (b *Builder) findPackagesJS(p *packages.Package, visited map[string]bool) (extraJS []string, err error) {
@@ -142,8 +300,8 @@ func (b *Builder) findPackagesJS(p *packages.Package, visited map[string]bool) (
 }
 
 // mergeJSFiles will merge all files into a single `wasm.js`. It will prepend the jsSetGo
-// and append the jsStartGo.
-func mergeJSFiles(dst string, files ...string) (err error) {
+// and the loader configuration, and append the jsStartGo.
+func mergeJSFiles(dst string, loader loaderConfig, files ...string) (err error) {
 	w, err := os.Create(dst)
 	if err != nil {
 		return err
@@ -153,9 +311,21 @@ func mergeJSFiles(dst string, files ...string) (err error) {
 			err = cerr
 		}
 	}()
+	return writeJSFiles(w, loader, files...)
+}
+
+// writeJSFiles writes the content of mergeJSFiles into w.
+func writeJSFiles(w io.Writer, loader loaderConfig, files ...string) (err error) {
 	if _, err = io.Copy(w, strings.NewReader(jsSetGo)); err != nil {
 		return err
 	}
+	cfg, err := json.Marshal(loader)
+	if err != nil {
+		return err
+	}
+	if _, err = fmt.Fprintf(w, jsLoaderConfig, cfg); err != nil {
+		return err
+	}
 	for i := range files {
 		r, err := os.Open(files[i])
 		if err != nil {
@@ -201,8 +371,130 @@ let go = undefined;
 	}
 })();`
 
+	// jsLoaderConfig sets the configuration used by jsStartGo.
+	jsLoaderConfig = `
+go["loader"] = %s;
+`
+
 	// jsStartGo initializes the main.wasm.
+	//
+	// It dispatches "inkwasm:progress" events (detail: {loaded, total}) while
+	// downloading, "inkwasm:error" events (detail: {kind, message}) when something
+	// fails and "inkwasm:exit" (detail: {code}) when the program exits.
 	jsStartGo = `(() => {
+	const loader = Object.assign({}, go["loader"], globalThis.InkwasmLoader);
+
+	const emit = (name, detail) => {
+		if (typeof CustomEvent === "function" && typeof globalThis.dispatchEvent === "function") {
+			globalThis.dispatchEvent(new CustomEvent("inkwasm:" + name, {detail: detail}));
+		}
+	};
+
+	// stderr holds the last output of fd 2, which is where Go writes panics.
+	let stderr = "";
+	if (globalThis.fs && typeof globalThis.fs.writeSync === "function") {
+		const decoder = new TextDecoder("utf-8");
+		const writeSync = globalThis.fs.writeSync;
+		globalThis.fs.writeSync = function (fd, buf) {
+			if (fd === 2) {
+				stderr = (stderr + decoder.decode(buf)).slice(-65536);
+			}
+			return writeSync.apply(this, arguments);
+		};
+	}
+
+	const overlay = (title, message) => {
+		if (!loader.overlay || typeof document === "undefined" || document.body === null) {
+			return;
+		}
+		let el = null;
+		if (typeof loader.overlay === "string") {
+			el = document.getElementById(loader.overlay);
+		}
+		if (el === null) {
+			el = document.createElement("div");
+			el.setAttribute("style", "position:fixed;inset:0;z-index:2147483647;overflow:auto;padding:2em;background:rgba(24,24,24,.95);color:#eee;font:14px/1.5 sans-serif");
+			el.innerHTML = '<h1 data-inkwasm-title style="font-size:1.5em"></h1><pre data-inkwasm-message style="white-space:pre-wrap"></pre><button onclick="location.reload()">Reload</button>';
+			document.body.appendChild(el);
+		}
+		const titleEl = el.querySelector("[data-inkwasm-title]");
+		if (titleEl !== null) {
+			titleEl.textContent = title;
+		}
+		const messageEl = el.querySelector("[data-inkwasm-message]");
+		if (messageEl !== null) {
+			messageEl.textContent = message;
+		}
+		el.hidden = false;
+		el.style.display = "";
+	};
+
+	const report = (kind, message) => {
+		if (!loader.crashReport) {
+			return;
+		}
+		fetch(loader.crashReport, {
+			method: "POST",
+			headers: {"Content-Type": "application/json"},
+			body: JSON.stringify({
+				kind: kind,
+				message: message,
+				url: typeof location !== "undefined" ? location.href : "",
+				userAgent: typeof navigator !== "undefined" ? navigator.userAgent : "",
+			}),
+		}).catch((e) => console.warn("crash report failed:", e));
+	};
+
+	const titles = {
+		fetch: "Failed to download the application",
+		instantiate: "Failed to start the application",
+		panic: "The application crashed",
+		exit: "The application exited unexpectedly",
+	};
+	const fail = (kind, message) => {
+		console.error(kind + ":", message);
+		emit("error", {kind: kind, message: message});
+		overlay(titles[kind] || titles.exit, message);
+		report(kind, message);
+	};
+
+	const embedded = (data) => {
+		const raw = atob(data);
+		const compressed = new Uint8Array(raw.length);
+		for (let i = 0; i < raw.length; i++) {
+			compressed[i] = raw.charCodeAt(i);
+		}
+		emit("progress", {loaded: compressed.length, total: compressed.length});
+		const stream = new Blob([compressed]).stream().pipeThrough(new DecompressionStream("gzip"));
+		return new Response(stream, {headers: {"Content-Type": "application/wasm"}});
+	};
+
+	const download = (url) => fetch(url).then((resp) => {
+		if (!resp.ok) {
+			throw new Error(url + ": " + resp.status + " " + resp.statusText);
+		}
+		const total = Number(resp.headers.get("Content-Length")) || 0;
+		if (!resp.body || typeof ReadableStream === "undefined") {
+			emit("progress", {loaded: total, total: total});
+			return resp;
+		}
+		let loaded = 0;
+		const reader = resp.body.getReader();
+		return new Response(new ReadableStream({
+			pull(controller) {
+				return reader.read().then(({done, value}) => {
+					if (done) {
+						controller.close();
+						return;
+					}
+					loaded += value.byteLength;
+					emit("progress", {loaded: loaded, total: total});
+					controller.enqueue(value);
+				});
+			},
+		}), {status: resp.status, statusText: resp.statusText, headers: resp.headers});
+	});
+
 	let defaultGo = new Go();
 	Object.assign(defaultGo["argv"], defaultGo["argv"].concat(go["argv"]));
 	Object.assign(defaultGo["env"], go["env"]);
@@ -215,7 +507,9 @@ let go = undefined;
 	defaultGo.exit = function(code) {
 		if (code !== 0) {
 			console.warn("exit code:", code);
+			fail(stderr.includes("panic: ") ? "panic" : "exit", stderr || "exit code: " + code);
 		}
+		emit("exit", {code: code});
 		globalThis._exit_code = code + 1;
 	};
 	go = defaultGo;
@@ -225,8 +519,18 @@ let go = undefined;
             return await WebAssembly.instantiate(source, importObject);
         };
     }
-    WebAssembly.instantiateStreaming(fetch("main.wasm"), go.importObject).then((result) => {
-        go.run(result.instance);
+    let stage = "fetch";
+    Promise.resolve().then(() => loader.embedded ? embedded(loader.embedded) : download(loader.wasm)).then((resp) => {
+        stage = "instantiate";
+        return WebAssembly.instantiateStreaming(resp, go.importObject);
+    }).then((result) => {
+        stage = "exit";
+        return go.run(result.instance);
+    }).catch((e) => {
+        fail(stage, String(e && e.stack ? e.stack : e));
+        if (globalThis._exit_code === null) {
+            globalThis._exit_code = 2;
+        }
     });
 })();
 })();`
@@ -0,0 +1,156 @@
+package build
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ProjectFile is the name of the project configuration file, it's
+// discovered from the working directory up to the root.
+const ProjectFile = "inkwasm.json"
+
+// Project is the content of the ProjectFile.
+//
+// The Settings are used by all commands, and each entry of
+// Targets (such as "build", "test" or "bench") overrides them for
+// the given command. Flags provided in the command line override
+// both.
+type Project struct {
+	Settings
+	Targets map[string]Settings `json:"targets,omitempty"`
+
+	// Dir is the directory of the ProjectFile.
+	Dir string `json:"-"`
+}
+
+// Settings is the configuration of one command. The bool fields are
+// pointers, so a target can turn off what the base configuration sets.
+type Settings struct {
+	Tags        string `json:"tags,omitempty"`
+	GCFlags     string `json:"gcflags,omitempty"`
+	Ldflags     string `json:"ldflags,omitempty"`
+	Output      string `json:"output,omitempty"`
+	Compiler    string `json:"compiler,omitempty"`
+	Runner      string `json:"runner,omitempty"`
+	Template    string `json:"template,omitempty"`
+	Overlay     string `json:"overlay,omitempty"`
+	CrashReport string `json:"crashReport,omitempty"`
+	Release     *bool  `json:"release,omitempty"`
+	SingleFile  *bool  `json:"singleFile,omitempty"`
+}
+
+// FindProject looks for the ProjectFile in dir and its parents. It
+// returns nil, without error, if there's no ProjectFile.
+func FindProject(dir string) (*Project, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		p, err := LoadProject(filepath.Join(dir, ProjectFile))
+		if err == nil {
+			return p, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// LoadProject reads the given ProjectFile.
+func LoadProject(path string) (*Project, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Project{Dir: filepath.Dir(path)}
+	if err := json.Unmarshal(b, p); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return p, nil
+}
+
+// Target returns the configuration for the given command, merging
+// the Targets entry, if any, into the base configuration. Relative
+// paths are resolved from the Dir.
+//
+// The "test" and "bench" targets don't inherit the base Output, so
+// tests are built into a temporary folder, instead of replacing the
+// build output.
+//
+// It's safe to call Target on nil Project.
+func (p *Project) Target(name string) Settings {
+	if p == nil {
+		return Settings{}
+	}
+
+	t := p.Settings.merge(p.Targets[name])
+	if name == "test" || name == "bench" {
+		t.Output = p.Targets[name].Output
+	}
+	for _, path := range []*string{&t.Output, &t.Template} {
+		if *path != "" && !filepath.IsAbs(*path) {
+			*path = filepath.Join(p.Dir, *path)
+		}
+	}
+	return t
+}
+
+func (t Settings) merge(o Settings) Settings {
+	for _, v := range []struct{ dst, src *string }{
+		{&t.Tags, &o.Tags},
+		{&t.GCFlags, &o.GCFlags},
+		{&t.Ldflags, &o.Ldflags},
+		{&t.Output, &o.Output},
+		{&t.Compiler, &o.Compiler},
+		{&t.Runner, &o.Runner},
+		{&t.Template, &o.Template},
+		{&t.Overlay, &o.Overlay},
+		{&t.CrashReport, &o.CrashReport},
+	} {
+		if *v.src != "" {
+			*v.dst = *v.src
+		}
+	}
+	for _, v := range []struct{ dst, src **bool }{
+		{&t.Release, &o.Release},
+		{&t.SingleFile, &o.SingleFile},
+	} {
+		if *v.src != nil {
+			*v.dst = *v.src
+		}
+	}
+	return t
+}
+
+// Apply sets the configuration into the given BuilderConfig and
+// TesterConfig, either can be nil.
+func (t Settings) Apply(build *BuilderConfig, test *TesterConfig) {
+	if build != nil {
+		build.Tags = t.Tags
+		build.GCFlags = t.GCFlags
+		build.Ldflags = t.Ldflags
+		build.Output = t.Output
+		build.Compiler = t.Compiler
+		build.Template = t.Template
+		build.Overlay = t.Overlay
+		build.CrashReport = t.CrashReport
+		build.SingleFile = t.SingleFile != nil && *t.SingleFile
+	}
+	if test != nil {
+		test.Runner = t.Runner
+	}
+}
@@ -0,0 +1,55 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProjectTarget(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ProjectFile)
+	err := os.WriteFile(path, []byte(`{
+	"output": "wasm-build",
+	"release": true,
+	"targets": {
+		"build": {"ldflags": "-X main.version=1"},
+		"bench": {"output": "bench-build", "release": false}
+	}
+}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := LoadProject(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		target  string
+		output  string
+		release bool
+	}{
+		{target: "build", output: filepath.Join(dir, "wasm-build"), release: true},
+		{target: "test", output: "", release: true},
+		{target: "bench", output: filepath.Join(dir, "bench-build"), release: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			s := p.Target(tt.target)
+			if s.Output != tt.output {
+				t.Errorf("unexpected output: %q, want %q", s.Output, tt.output)
+			}
+			if release := s.Release != nil && *s.Release; release != tt.release {
+				t.Errorf("unexpected release: %v, want %v", release, tt.release)
+			}
+
+			var config BuilderConfig
+			s.Apply(&config, nil)
+			if config.Output != tt.output {
+				t.Errorf("unexpected BuilderConfig.Output: %q, want %q", config.Output, tt.output)
+			}
+		})
+	}
+}
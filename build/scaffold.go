@@ -0,0 +1,113 @@
+package build
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ScaffoldFile is one file created by Scaffold.
+type ScaffoldFile struct {
+	Path    string
+	Skipped bool // Skipped is true if the file already exists
+}
+
+// Scaffold creates a starter project in the given directory. Existing
+// files are never replaced.
+func Scaffold(dir string) ([]ScaffoldFile, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	var created []ScaffoldFile
+	for _, f := range []struct {
+		Name    string
+		Content string
+	}{
+		{Name: "main.go", Content: scaffoldMain},
+		{Name: "greet_js.js", Content: scaffoldJS},
+		{Name: "main_js_test.go", Content: scaffoldTest},
+		{Name: ProjectFile, Content: scaffoldProject},
+		{Name: filepath.Join("web", "index.html"), Content: jsIndex + "\n"},
+		{Name: ".gitignore", Content: scaffoldGitignore},
+	} {
+		path := filepath.Join(dir, f.Name)
+		if _, err := os.Stat(path); err == nil {
+			created = append(created, ScaffoldFile{Path: path, Skipped: true})
+			continue
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return created, err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return created, err
+		}
+		if err := os.WriteFile(path, []byte(f.Content), 0644); err != nil {
+			return created, err
+		}
+		created = append(created, ScaffoldFile{Path: path})
+	}
+
+	return created, nil
+}
+
+const (
+	scaffoldMain = `//go:build js && wasm
+
+package main
+
+import (
+	"github.com/inkeliz/go_inkwasm/inkwasm"
+)
+
+func main() {
+	body := getBody()
+	defer body.Free()
+
+	setInnerHTML(body, greet("Gopher"))
+}
+
+// greet is implemented in greet_js.js, which is merged into the wasm.js.
+//
+//inkwasm:func globalThis.greet
+func greet(name string) string
+
+//inkwasm:get globalThis.document.body
+func getBody() inkwasm.Object
+
+//inkwasm:set .innerHTML
+func setInnerHTML(o inkwasm.Object, html string)
+`
+
+	scaffoldJS = `(() => {
+    globalThis.greet = function (name) {
+        return "Hello, " + name + "!"
+    }
+})();
+`
+
+	scaffoldTest = `package main
+
+import (
+	"testing"
+)
+
+func TestGreet(t *testing.T) {
+	if r := greet("Gopher"); r != "Hello, Gopher!" {
+		t.Errorf("unexpected greeting: %q", r)
+	}
+}
+`
+
+	scaffoldProject = `{
+	"template": "web/index.html",
+	"targets": {
+		"build": {"release": true}
+	}
+}
+`
+
+	scaffoldGitignore = `/wasm-build/
+`
+)
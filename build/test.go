@@ -24,6 +24,7 @@ That file is based on https://github.com/agnivade/wasmbrowsertest
 type TesterConfig struct {
 	*BuilderConfig
 	Port     string
+	Runner   string // Runner is the browser executable, if empty it's auto-detected
 	BenchRun string
 	Count    string
 	Time     string
@@ -47,7 +48,9 @@ func (t *Tester) Build() error {
 	cmd := exec.Command(
 		t.builder.config.Compiler,
 		"test",
+		"-ldflags="+t.builder.config.Ldflags,
 		"-tags="+t.builder.config.Tags,
+		"-gcflags="+t.builder.config.GCFlags,
 		"-c",
 		"-o="+filepath.Join(t.builder.config.Output, "main.wasm"),
 		t.builder.config.Source,
@@ -87,6 +90,9 @@ func (t *Tester) Run() error {
 
 	opts := chromedp.DefaultExecAllocatorOptions[:]
 	opts = append(opts, chromedp.Flag("headless", false))
+	if t.config.Runner != "" {
+		opts = append(opts, chromedp.ExecPath(t.config.Runner))
+	}
 
 	// create chrome instance
 	allocCtx, cancelAllocCtx := chromedp.NewExecAllocator(context.Background(), opts...)
@@ -0,0 +1,12 @@
+// Command inkwasmvet checks the //inkwasm: directives and the lifetime
+// of inkwasm.Object values, see the inkwasmvet package.
+package main
+
+import (
+	"github.com/inkeliz/go_inkwasm/inkwasmvet"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(inkwasmvet.Analyzer)
+}
@@ -0,0 +1,213 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines around each hunk.
+const diffContext = 3
+
+type diffOp uint8
+
+const (
+	diffEqual diffOp = iota
+	diffDelete
+	diffInsert
+)
+
+type diffLine struct {
+	Op   diffOp
+	Text string
+}
+
+// unifiedDiff returns the unified diff between a and b, or nil if both
+// are equal.
+func unifiedDiff(nameA, nameB string, a, b []byte) []byte {
+	if bytes.Equal(a, b) {
+		return nil
+	}
+
+	lines := diffLines(splitLines(a), splitLines(b))
+
+	out := bytes.NewBuffer(nil)
+	fmt.Fprintf(out, "--- %s\n+++ %s\n", nameA, nameB)
+
+	for start := 0; start < len(lines); {
+		// Find the next change, and the end of the hunk, which is
+		// when there's more than 2*diffContext unchanged lines.
+		first := start
+		for first < len(lines) && lines[first].Op == diffEqual {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+		last, equal := first, 0
+		for i := first; i < len(lines) && equal <= 2*diffContext; i++ {
+			if lines[i].Op == diffEqual {
+				equal++
+			} else {
+				last, equal = i, 0
+			}
+		}
+
+		from := first - diffContext
+		if from < start {
+			from = start
+		}
+		to := last + diffContext + 1
+		if to > len(lines) {
+			to = len(lines)
+		}
+
+		// Line numbers of the hunk, on both sides.
+		lineA, lineB := 1, 1
+		for _, l := range lines[:from] {
+			if l.Op != diffInsert {
+				lineA++
+			}
+			if l.Op != diffDelete {
+				lineB++
+			}
+		}
+		countA, countB := 0, 0
+		for _, l := range lines[from:to] {
+			if l.Op != diffInsert {
+				countA++
+			}
+			if l.Op != diffDelete {
+				countB++
+			}
+		}
+		if countA == 0 {
+			lineA--
+		}
+		if countB == 0 {
+			lineB--
+		}
+
+		fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", lineA, countA, lineB, countB)
+		for _, l := range lines[from:to] {
+			switch l.Op {
+			case diffEqual:
+				out.WriteByte(' ')
+			case diffDelete:
+				out.WriteByte('-')
+			case diffInsert:
+				out.WriteByte('+')
+			}
+			out.WriteString(l.Text)
+			if !strings.HasSuffix(l.Text, "\n") {
+				out.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		start = to
+	}
+
+	return out.Bytes()
+}
+
+func splitLines(b []byte) []string {
+	lines := strings.SplitAfter(string(b), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines uses the Myers' algorithm to compute the shortest edit
+// script between a and b.
+func diffLines(a, b []string) []diffLine {
+	// Common prefix and suffix are trimmed, since generated files usually
+	// differ in small regions.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var lines []diffLine
+	for _, l := range a[:prefix] {
+		lines = append(lines, diffLine{Op: diffEqual, Text: l})
+	}
+	lines = append(lines, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, l := range a[len(a)-suffix:] {
+		lines = append(lines, diffLine{Op: diffEqual, Text: l})
+	}
+	return lines
+}
+
+func myers(a, b []string) []diffLine {
+	n, m := len(a), len(b)
+	max := n + m
+	if max == 0 {
+		return nil
+	}
+
+	v := make([]int, 2*max+2)
+	var trace [][]int
+
+search:
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[max+k-1] < v[max+k+1]) {
+				x = v[max+k+1]
+			} else {
+				x = v[max+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[max+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	var reversed []diffLine
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		v := trace[d]
+		k := x - y
+
+		prevK := k - 1
+		if k == -d || (k != d && v[max+k-1] < v[max+k+1]) {
+			prevK = k + 1
+		}
+		prevX := v[max+prevK]
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, diffLine{Op: diffEqual, Text: a[x-1]})
+			x, y = x-1, y-1
+		}
+		if x == prevX {
+			reversed = append(reversed, diffLine{Op: diffInsert, Text: b[y-1]})
+			y--
+		} else {
+			reversed = append(reversed, diffLine{Op: diffDelete, Text: a[x-1]})
+			x--
+		}
+	}
+	for x > 0 && y > 0 {
+		reversed = append(reversed, diffLine{Op: diffEqual, Text: a[x-1]})
+		x, y = x-1, y-1
+	}
+
+	lines := make([]diffLine, len(reversed))
+	for i, l := range reversed {
+		lines[len(lines)-1-i] = l
+	}
+	return lines
+}
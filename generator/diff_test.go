@@ -0,0 +1,152 @@
+package generator
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	// The expected results match the output of "diff -u".
+	lines := func(n int, change map[int]string) string {
+		s := ""
+		for i := 1; i <= n; i++ {
+			if v, ok := change[i]; ok {
+				s += v + "\n"
+			} else {
+				s += strconv.Itoa(i) + "\n"
+			}
+		}
+		return s
+	}
+
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{
+			name: "equal",
+			a:    "a\nb\n",
+			b:    "a\nb\n",
+			want: "",
+		},
+		{
+			name: "insert only",
+			a:    "",
+			b:    "a\nb\n",
+			want: `--- a
++++ b
+@@ -0,0 +1,2 @@
++a
++b
+`,
+		},
+		{
+			name: "delete only",
+			a:    "a\nb\n",
+			b:    "",
+			want: `--- a
++++ b
+@@ -1,2 +0,0 @@
+-a
+-b
+`,
+		},
+		{
+			name: "middle",
+			a:    lines(10, nil),
+			b:    lines(10, map[int]string{5: "five"}),
+			want: `--- a
++++ b
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`,
+		},
+		{
+			name: "merged and split hunks",
+			a:    lines(20, nil),
+			b:    lines(20, map[int]string{3: "three", 10: "ten", 18: "eighteen"}),
+			want: `--- a
++++ b
+@@ -1,13 +1,13 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+ 7
+ 8
+ 9
+-10
++ten
+ 11
+ 12
+ 13
+@@ -15,6 +15,6 @@
+ 15
+ 16
+ 17
+-18
++eighteen
+ 19
+ 20
+`,
+		},
+		{
+			name: "append",
+			a:    "a\nb\n",
+			b:    "a\nb\nc\n",
+			want: `--- a
++++ b
+@@ -1,2 +1,3 @@
+ a
+ b
++c
+`,
+		},
+		{
+			name: "no newline at end",
+			a:    "a\nb",
+			b:    "a\nc",
+			want: `--- a
++++ b
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++c
+\ No newline at end of file
+`,
+		},
+		{
+			name: "newline added at end",
+			a:    "a\nb",
+			b:    "a\nb\n",
+			want: `--- a
++++ b
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++b
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := unifiedDiff("a", "b", []byte(tt.a), []byte(tt.b))
+			if string(got) != tt.want {
+				t.Errorf("unexpected diff\ngot:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
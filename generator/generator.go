@@ -0,0 +1,419 @@
+// Package generator creates the Golang, Assembly and Javascript files for
+// the //inkwasm: directives, it's the same generator used by the inkwasm
+// command.
+//
+// It can be used by other build tools, or from go:generate:
+//
+//	//go:generate go run github.com/inkeliz/go_inkwasm generate .
+package generator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/inkeliz/go_inkwasm/bind"
+	"github.com/inkeliz/go_inkwasm/parser"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/tools/imports"
+)
+
+// Options are the options for Generate.
+type Options struct {
+	// Dir is the directory used to load the Packages, if empty it
+	// uses the current directory.
+	Dir string
+	// Packages are the patterns of the packages, such as ".", all
+	// imported packages are also generated.
+	Packages []string
+	// Output returns the directory where the files of the given package
+	// are created. If nil, the files are created in the package directory.
+	Output func(pkg bind.Package) string
+	// Modes are the modes to generate, if empty it uses both parser.Release
+	// and parser.Test.
+	Modes []parser.Mode
+	// DryRun reports the files without changing them.
+	DryRun bool
+	// MainModule only reports the files of the packages of the main
+	// module. Imported packages of other modules are still parsed, but
+	// their files are never changed.
+	MainModule bool
+}
+
+// Action is what Generate does with one File.
+type Action uint8
+
+const (
+	// ActionNone means that the file is already up-to-date, or
+	// doesn't exist and doesn't need to.
+	ActionNone Action = iota
+	// ActionWrite means that the file is created or replaced.
+	ActionWrite
+	// ActionRemove means that the file is removed, since there's
+	// nothing to generate.
+	ActionRemove
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionNone:
+		return "none"
+	case ActionWrite:
+		return "write"
+	case ActionRemove:
+		return "remove"
+	default:
+		return ""
+	}
+}
+
+// File is one generated file.
+type File struct {
+	Package bind.Package
+	Mode    parser.Mode
+	Path    string
+	// Content is the generated content, formatted with goimports
+	// for Golang files. It's empty if there's nothing to generate.
+	Content []byte
+	Action  Action
+	// Orphan is true if the package doesn't have any directive, but
+	// still have generated files.
+	Orphan bool
+}
+
+// Diff returns the unified diff between the existing file and the
+// Content, or nil if there's no change.
+func (f File) Diff() ([]byte, error) {
+	existing, err := os.ReadFile(f.Path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	return unifiedDiff(f.Path, f.Path, existing, f.Content), nil
+}
+
+// Result is the result of Generate.
+type Result struct {
+	Files []File
+	// Diagnostics are the problems found in the packages, files of
+	// packages with problems are not changed.
+	Diagnostics bind.Diagnostics
+}
+
+// Generate creates the files for the given packages, and their imports.
+//
+// The returned error is the Diagnostics, if any, or an error which
+// prevents generating any file, such as failing to load the packages.
+func Generate(ctx context.Context, opts Options) (Result, error) {
+	var result Result
+
+	if len(opts.Packages) == 0 {
+		opts.Packages = []string{"."}
+	}
+	if len(opts.Modes) == 0 {
+		opts.Modes = []parser.Mode{parser.Release, parser.Test}
+	}
+
+	p := parser.NewParser()
+	p.PackagesConfig.Context = ctx
+	p.PackagesConfig.Dir = opts.Dir
+
+	m, err := p.ParsePackages(opts.Packages...)
+	if err != nil {
+		diags, ok := err.(bind.Diagnostics)
+		if !ok {
+			return result, err
+		}
+		result.Diagnostics = append(result.Diagnostics, diags...)
+	}
+
+	// Files of packages with problems are not changed, even if some
+	// functions are valid. They are still bound, to report the problems
+	// of the valid functions.
+	invalid := make(map[string]bool)
+	for _, d := range result.Diagnostics {
+		invalid[filepath.Dir(d.File)] = true
+	}
+
+	pkgs := make([]bind.Package, 0, len(m))
+	for pkg := range m {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].Path < pkgs[j].Path })
+
+	inMain := make(map[bind.Package]bool, len(m))
+	for _, pkg := range mainPackages(p, pkgs) {
+		inMain[pkg] = true
+	}
+
+	// The binders are not concurrent safe, since exported structs
+	// are registered globally.
+	used := make(map[string]bool, len(m))
+	for _, pkg := range pkgs {
+		used[pkg.Dir] = true
+		files, err := bindPackage(pkg, m[pkg], opts)
+		if err != nil {
+			diags, ok := err.(bind.Diagnostics)
+			if !ok {
+				return result, err
+			}
+			result.Diagnostics = append(result.Diagnostics, diags...)
+			continue
+		}
+		if !invalid[pkg.Dir] && (inMain[pkg] || !opts.MainModule) {
+			result.Files = append(result.Files, files...)
+		}
+	}
+	for dir := range invalid {
+		used[dir] = true
+	}
+
+	// Files of dependencies, such as in the module cache, are not
+	// reported as orphans, since they can't be changed.
+	orphans, err := orphanFiles(mainPackages(p, p.Packages()), used, opts)
+	if err != nil {
+		return result, err
+	}
+	result.Files = append(result.Files, orphans...)
+
+	var wg errgroup.Group
+	for i := range result.Files {
+		f := &result.Files[i]
+		wg.Go(func() error {
+			return f.prepare(opts.DryRun)
+		})
+	}
+	if err := wg.Wait(); err != nil {
+		return result, err
+	}
+
+	return result, result.Diagnostics.Err()
+}
+
+// Clean removes the generated files of the given packages, and their
+// imports from the main module. Only files with the parser.Header are
+// removed, so files written by hand, even if using the same name, are
+// kept. Files of dependencies, and of the inkwasm package itself, even
+// if it's part of the main module, are never removed.
+func Clean(ctx context.Context, opts Options) (Result, error) {
+	var result Result
+
+	if len(opts.Packages) == 0 {
+		opts.Packages = []string{"."}
+	}
+
+	p := parser.NewParser()
+	p.PackagesConfig.Context = ctx
+	p.PackagesConfig.Dir = opts.Dir
+
+	// Packages with problems are also cleaned.
+	if _, err := p.ParsePackages(opts.Packages...); err != nil {
+		if _, ok := err.(bind.Diagnostics); !ok {
+			return result, err
+		}
+	}
+
+	var pkgs []bind.Package
+	for _, pkg := range mainPackages(p, p.Packages()) {
+		if pkg.Path == "github.com/inkeliz/go_inkwasm/inkwasm" {
+			// The runtime needs its own generated files.
+			continue
+		}
+		pkgs = append(pkgs, pkg)
+	}
+
+	// Packages with directives are also cleaned, so all packages are
+	// treated as orphans.
+	files, err := orphanFiles(pkgs, map[string]bool{}, opts)
+	if err != nil {
+		return result, err
+	}
+
+	for _, f := range files {
+		f.Orphan = false
+		f.Action = ActionRemove
+		if !opts.DryRun {
+			if err := os.Remove(f.Path); err != nil {
+				return result, err
+			}
+		}
+		result.Files = append(result.Files, f)
+	}
+
+	return result, nil
+}
+
+// mainPackages returns the packages of the main module, or without
+// any module.
+func mainPackages(p *parser.Parser, all []bind.Package) []bind.Package {
+	var pkgs []bind.Package
+	for _, pkg := range all {
+		if mod := p.Module(pkg); mod == nil || mod.Main {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	return pkgs
+}
+
+// bindPackage returns the files, without any Action, for the given package.
+func bindPackage(pkg bind.Package, functions []*bind.Function, opts Options) ([]File, error) {
+	dir := pkg.Dir
+	if opts.Output != nil {
+		dir = opts.Output(pkg)
+	}
+
+	var files []File
+	for _, mode := range opts.Modes {
+		binder := parser.NewBinder(mode)
+		if err := binder.Create(pkg, functions); err != nil {
+			return nil, err
+		}
+
+		suffix := ""
+		if mode == parser.Test {
+			suffix = "_test"
+		}
+
+		for _, v := range []struct {
+			Source func() io.Reader
+			Ext    string
+		}{
+			{Source: binder.JS, Ext: ".js"},
+			{Source: binder.ASM, Ext: ".s"},
+			{Source: binder.GO, Ext: ".go"},
+		} {
+			content, err := io.ReadAll(v.Source())
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, File{
+				Package: pkg,
+				Mode:    mode,
+				Path:    filepath.Join(dir, "inkwasm_js"+suffix+v.Ext),
+				Content: content,
+			})
+		}
+	}
+
+	return files, nil
+}
+
+// orphanFiles returns the generated files of the packages which are not
+// used, usually the ones without any directive. Files without the
+// parser.Header are ignored. If opts.Modes is given, only the files of
+// those modes are returned.
+func orphanFiles(all []bind.Package, used map[string]bool, opts Options) ([]File, error) {
+	names := Names
+	if len(opts.Modes) > 0 {
+		names = nil
+		for _, name := range Names {
+			mode := parser.Release
+			if strings.Contains(name, "_test") {
+				mode = parser.Test
+			}
+			if slices.Contains(opts.Modes, mode) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	var files []File
+	for _, pkg := range all {
+		if used[pkg.Dir] {
+			continue
+		}
+		used[pkg.Dir] = true
+
+		dir := pkg.Dir
+		if opts.Output != nil {
+			dir = opts.Output(pkg)
+		}
+
+		for _, name := range names {
+			path := filepath.Join(dir, name)
+			generated, err := IsGenerated(path)
+			if err != nil {
+				return nil, err
+			}
+			if generated {
+				files = append(files, File{Package: pkg, Path: path, Orphan: true})
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// Names are the names of all files created by the generator.
+var Names = []string{
+	"inkwasm_js.js", "inkwasm_js.s", "inkwasm_js.go",
+	"inkwasm_js_test.js", "inkwasm_js_test.s", "inkwasm_js_test.go",
+}
+
+// IsGenerated reports whether the file starts with the parser.Header.
+// It returns false, without error, if the file doesn't exist.
+func IsGenerated(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer f.Close()
+
+	header := make([]byte, len(parser.Header))
+	if _, err := io.ReadFull(f, header); err != nil {
+		return false, nil
+	}
+	return string(header) == parser.Header, nil
+}
+
+// prepare formats the Content and defines the Action, comparing it with
+// the existing file. If not dryRun, it also executes the Action.
+func (f *File) prepare(dryRun bool) error {
+	if len(f.Content) > 0 && filepath.Ext(f.Path) == ".go" {
+		content, err := imports.Process(f.Path, f.Content, nil)
+		if err != nil {
+			return err
+		}
+		f.Content = content
+	}
+
+	existing, err := os.ReadFile(f.Path)
+	exists := err == nil
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	switch {
+	case len(f.Content) == 0 && exists:
+		f.Action = ActionRemove
+	case len(f.Content) > 0 && (!exists || !bytes.Equal(existing, f.Content)):
+		f.Action = ActionWrite
+	default:
+		f.Action = ActionNone
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	switch f.Action {
+	case ActionWrite:
+		if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+			return err
+		}
+		return os.WriteFile(f.Path, f.Content, 0666)
+	case ActionRemove:
+		return os.Remove(f.Path)
+	default:
+		return nil
+	}
+}
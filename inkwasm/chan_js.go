@@ -0,0 +1,206 @@
+package inkwasm
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"syscall/js"
+)
+
+// Value are the types which can be decoded from Object, used by
+// channels and maps, such as ReceiveChan and DecodeMap.
+type Value interface {
+	Object | string | bool | []byte |
+		int | int8 | int16 | int32 | uint | uint8 | uint16 | uint32 |
+		float32 | float64
+}
+
+// ReceiveChan returns a channel which receives the values of the
+// given Object, which can be an async iterable, such as ReadableStream
+// and async generators, or an iterable, such as Array.
+//
+// The channel is closed when the iterable is done, or rejected, or when
+// the ctx is done, which cancels the iterable using the return method,
+// even if the next value is still pending.
+//
+// The given Object is released. The received Object values must be
+// released using Free, when no longer in use.
+func ReceiveChan[T Value](ctx context.Context, src Object) <-chan T {
+	ch := make(chan T)
+
+	it := chanIterator(src)
+	src.Free()
+
+	go func() {
+		defer close(ch)
+		defer it.Free()
+
+		signal := make(chan struct{}, 1)
+		fn := js.FuncOf(func(this js.Value, args []js.Value) any {
+			signal <- struct{}{}
+			return nil
+		})
+		defer fn.Release()
+
+		f := NewObjectFromSyscall(fn.Value)
+		defer f.Free()
+
+		for {
+			r := chanNext(it, f)
+			select {
+			case <-signal:
+			case <-ctx.Done():
+				// The fn isn't called after the return, so it
+				// can be released.
+				r.Free()
+				chanReturn(it)
+				return
+			}
+			if chanDone(r) {
+				r.Free()
+				return
+			}
+			v := decodeValue[T](chanValue(r))
+			r.Free()
+			select {
+			case ch <- v:
+			case <-ctx.Done():
+				if o, ok := any(v).(Object); ok {
+					o.Free()
+				}
+				chanReturn(it)
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+// SendChan returns an async iterable, which receives the values of
+// the given channel, until the channel is closed. Each value is
+// received when requested by Javascript, such as using "for await".
+//
+// Cancelling the iterable, such as using "break", stops receiving
+// from the channel, which isn't closed.
+//
+// The resulting Object must be released using Free, when
+// no longer in use.
+func SendChan[T Value](ch <-chan T) Object {
+	var (
+		wants  = make(chan struct{})
+		cancel = make(chan struct{})
+		once   sync.Once
+	)
+
+	fn := js.FuncOf(func(this js.Value, args []js.Value) any {
+		if args[0].Bool() {
+			once.Do(func() { close(cancel) })
+			return nil
+		}
+		go func() {
+			select {
+			case wants <- struct{}{}:
+			case <-cancel:
+			}
+		}()
+		return nil
+	})
+
+	f := NewObjectFromSyscall(fn.Value)
+	r := chanIterable(f)
+	f.Free()
+
+	it, push := r.GetIndex(0), r.GetIndex(1)
+	r.Free()
+
+	go func() {
+		defer fn.Release()
+		defer push.Free()
+
+		for {
+			select {
+			case <-wants:
+			case <-cancel:
+				return
+			}
+			select {
+			case v, ok := <-ch:
+				chanPush(push, !ok, v)
+				if !ok {
+					return
+				}
+			case <-cancel:
+				return
+			}
+		}
+	}()
+
+	return it
+}
+
+// decodeValue converts the Object to T, the Object is released,
+// unless T is Object. Numbers can be strings, such as the keys
+// of JS objects.
+func decodeValue[T Value](o Object) (v T) {
+	if p, ok := any(&v).(*Object); ok {
+		*p = o
+		return v
+	}
+	defer o.Free()
+
+	f, _ := o.Float()
+	if o.typ == TypeString {
+		f, _ = strconv.ParseFloat(o.MustString(), 64)
+	}
+	switch p := any(&v).(type) {
+	case *string:
+		*p = o.MustString()
+	case *bool:
+		*p = o.Truthy()
+	case *[]byte:
+		*p = o.MustBytes(nil)
+	case *int:
+		*p = int(f)
+	case *int8:
+		*p = int8(f)
+	case *int16:
+		*p = int16(f)
+	case *int32:
+		*p = int32(f)
+	case *uint:
+		*p = uint(f)
+	case *uint8:
+		*p = uint8(f)
+	case *uint16:
+		*p = uint16(f)
+	case *uint32:
+		*p = uint32(f)
+	case *float32:
+		*p = float32(f)
+	case *float64:
+		*p = f
+	}
+	return v
+}
+
+//inkwasm:func globalThis.inkwasm.Internal.Iterator
+func chanIterator(o Object) Object
+
+//inkwasm:func globalThis.inkwasm.Internal.Next
+func chanNext(it Object, fn Object) Object
+
+//inkwasm:get .done
+func chanDone(r Object) bool
+
+//inkwasm:get .value
+func chanValue(r Object) Object
+
+//inkwasm:func globalThis.inkwasm.Internal.Return
+func chanReturn(it Object)
+
+//inkwasm:func globalThis.inkwasm.Internal.Iterable
+func chanIterable(fn Object) Object
+
+//inkwasm:func $0($1, $2)
+func chanPush(push Object, end bool, v interface{})
@@ -6,6 +6,67 @@ import (
 	"runtime"
 )
 
+func _chanIterator(o Object) (_ Object) {
+	r0 := __chanIterator(o)
+
+	return r0
+}
+
+//go:wasmimport gojs github.com/inkeliz/go_inkwasm/inkwasm.__chanIterator
+func __chanIterator(o Object) (_ Object)
+
+func _chanNext(it Object, fn Object) (_ Object) {
+	r0 := __chanNext(it, fn)
+
+	return r0
+}
+
+//go:wasmimport gojs github.com/inkeliz/go_inkwasm/inkwasm.__chanNext
+func __chanNext(it Object, fn Object) (_ Object)
+
+func _chanDone(r Object) (_ bool) {
+	r0 := __chanDone(r)
+
+	return r0
+}
+
+//go:wasmimport gojs github.com/inkeliz/go_inkwasm/inkwasm.__chanDone
+func __chanDone(r Object) (_ bool)
+
+func _chanValue(r Object) (_ Object) {
+	r0 := __chanValue(r)
+
+	return r0
+}
+
+//go:wasmimport gojs github.com/inkeliz/go_inkwasm/inkwasm.__chanValue
+func __chanValue(r Object) (_ Object)
+
+func _chanReturn(it Object) {
+	__chanReturn(it)
+
+}
+
+//go:wasmimport gojs github.com/inkeliz/go_inkwasm/inkwasm.__chanReturn
+func __chanReturn(it Object)
+
+func _chanIterable(fn Object) (_ Object) {
+	r0 := __chanIterable(fn)
+
+	return r0
+}
+
+//go:wasmimport gojs github.com/inkeliz/go_inkwasm/inkwasm.__chanIterable
+func __chanIterable(fn Object) (_ Object)
+
+func _chanPush(push Object, end bool, v interface{}) {
+	__chanPush(push, end, v)
+
+}
+
+//go:wasmimport gojs github.com/inkeliz/go_inkwasm/inkwasm.__chanPush
+func __chanPush(push Object, end bool, v interface{})
+
 func _newObjectFromSyscall(i uint32) (_ Object) {
 	r0 := __newObjectFromSyscall(i)
 
@@ -168,6 +229,15 @@ func _copyBytes(o Object, buf []byte) {
 //go:wasmimport gojs github.com/inkeliz/go_inkwasm/inkwasm.__copyBytes
 func __copyBytes(o Object, buf []byte)
 
+func _encodeBigInt(o Object) (_ Object) {
+	r0 := __encodeBigInt(o)
+
+	return r0
+}
+
+//go:wasmimport gojs github.com/inkeliz/go_inkwasm/inkwasm.__encodeBigInt
+func __encodeBigInt(o Object) (_ Object)
+
 func _instanceOf(o Object, v Object) (_ bool) {
 	r0 := __instanceOf(o, v)
 
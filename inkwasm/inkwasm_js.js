@@ -1,7 +1,7 @@
 // Code generated by INKWASM BUILD; DO NOT EDIT
 (() => {
 	if (globalThis.inkwasm === undefined) {
-		globalThis.inkwasm = {Load: {}};
+		globalThis.inkwasm = {Load: {}, Set: {}};
 	}
 
 })();
@@ -9,6 +9,49 @@
 (() => {
 	Object.assign(go.importObject.gojs, {
 
+		"github.com/inkeliz/go_inkwasm/inkwasm.__chanIterator": (sp) => {
+			let r = globalThis.inkwasm.Internal.Iterator(globalThis.inkwasm.Load.InkwasmObject(go, sp, 8))
+			sp = go._inst.exports.getsp() >>> 0
+			globalThis.inkwasm.Set.InkwasmObject(go, sp, 24, r)
+		},
+
+		"github.com/inkeliz/go_inkwasm/inkwasm.__chanNext": (sp) => {
+			let r = globalThis.inkwasm.Internal.Next(globalThis.inkwasm.Load.InkwasmObject(go, sp, 8),globalThis.inkwasm.Load.InkwasmObject(go, sp, 24))
+			sp = go._inst.exports.getsp() >>> 0
+			globalThis.inkwasm.Set.InkwasmObject(go, sp, 40, r)
+		},
+
+		"github.com/inkeliz/go_inkwasm/inkwasm.__chanDone": (sp) => {
+			let r = globalThis.inkwasm.Load.InkwasmObject(go, sp, 8).done
+			sp = go._inst.exports.getsp() >>> 0
+			globalThis.inkwasm.Set.Bool(go, sp, 24, r)
+		},
+
+		"github.com/inkeliz/go_inkwasm/inkwasm.__chanValue": (sp) => {
+			let r = globalThis.inkwasm.Load.InkwasmObject(go, sp, 8).value
+			sp = go._inst.exports.getsp() >>> 0
+			globalThis.inkwasm.Set.InkwasmObject(go, sp, 24, r)
+		},
+
+		"github.com/inkeliz/go_inkwasm/inkwasm.__chanReturn": (sp) => {
+			globalThis.inkwasm.Internal.Return(globalThis.inkwasm.Load.InkwasmObject(go, sp, 8))
+
+		},
+
+		"github.com/inkeliz/go_inkwasm/inkwasm.__chanIterable": (sp) => {
+			let r = globalThis.inkwasm.Internal.Iterable(globalThis.inkwasm.Load.InkwasmObject(go, sp, 8))
+			sp = go._inst.exports.getsp() >>> 0
+			globalThis.inkwasm.Set.InkwasmObject(go, sp, 24, r)
+		},
+
+		"github.com/inkeliz/go_inkwasm/inkwasm.__chanPush": (sp) => {
+			const $0 = globalThis.inkwasm.Load.InkwasmObject(go, sp, 8)
+			const $1 = globalThis.inkwasm.Load.Bool(go, sp, 24)
+			const $2 = globalThis.inkwasm.Load.Interface(go, sp, 32)
+			$0($1, $2)
+
+		},
+
 		"github.com/inkeliz/go_inkwasm/inkwasm.__newObjectFromSyscall": (sp) => {
 			let r = go._values[globalThis.inkwasm.Load.Uint32(go, sp, 8)]
 			sp = go._inst.exports.getsp() >>> 0
@@ -137,6 +180,12 @@
 
 		},
 
+		"github.com/inkeliz/go_inkwasm/inkwasm.__encodeBigInt": (sp) => {
+			let r = globalThis.inkwasm.Internal.EncodeBigInt(globalThis.inkwasm.Load.InkwasmObject(go, sp, 8))
+			sp = go._inst.exports.getsp() >>> 0
+			globalThis.inkwasm.Set.InkwasmObject(go, sp, 24, r)
+		},
+
 		"github.com/inkeliz/go_inkwasm/inkwasm.__instanceOf": (sp) => {
 			let r = globalThis.inkwasm.Internal.InstanceOf(globalThis.inkwasm.Load.InkwasmObject(go, sp, 8),globalThis.inkwasm.Load.InkwasmObject(go, sp, 24))
 			sp = go._inst.exports.getsp() >>> 0
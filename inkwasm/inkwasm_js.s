@@ -1,6 +1,34 @@
 // Code generated by INKWASM BUILD; DO NOT EDIT
 #include "textflag.h"
 
+TEXT ·chanIterator(SB), NOSPLIT, $0
+	JMP ·_chanIterator(SB)
+	RET
+
+TEXT ·chanNext(SB), NOSPLIT, $0
+	JMP ·_chanNext(SB)
+	RET
+
+TEXT ·chanDone(SB), NOSPLIT, $0
+	JMP ·_chanDone(SB)
+	RET
+
+TEXT ·chanValue(SB), NOSPLIT, $0
+	JMP ·_chanValue(SB)
+	RET
+
+TEXT ·chanReturn(SB), NOSPLIT, $0
+	JMP ·_chanReturn(SB)
+	RET
+
+TEXT ·chanIterable(SB), NOSPLIT, $0
+	JMP ·_chanIterable(SB)
+	RET
+
+TEXT ·chanPush(SB), NOSPLIT, $0
+	JMP ·_chanPush(SB)
+	RET
+
 TEXT ·newObjectFromSyscall(SB), NOSPLIT, $0
 	JMP ·_newObjectFromSyscall(SB)
 	RET
@@ -69,6 +97,10 @@ TEXT ·copyBytes(SB), NOSPLIT, $0
 	JMP ·_copyBytes(SB)
 	RET
 
+TEXT ·encodeBigInt(SB), NOSPLIT, $0
+	JMP ·_encodeBigInt(SB)
+	RET
+
 TEXT ·instanceOf(SB), NOSPLIT, $0
 	JMP ·_instanceOf(SB)
 	RET
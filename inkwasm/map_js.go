@@ -0,0 +1,46 @@
+package inkwasm
+
+// MapKey are the types of the keys of maps, used by DecodeMap.
+type MapKey interface {
+	string | bool |
+		int | int8 | int16 | int32 | uint | uint8 | uint16 | uint32 |
+		float32 | float64
+}
+
+// MapEntries returns the keys and values of the map, in the same
+// order. The generated code uses it to give maps to Javascript.
+func MapEntries[K comparable, V any](m map[K]V) (keys []K, values []V) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+	keys, values = make([]K, 0, len(m)), make([]V, 0, len(m))
+	for k, v := range m {
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+	return keys, values
+}
+
+// DecodeMap returns the map of the given Object, which must be the
+// entries of a JS object or Map, as [keys, values]. The result is
+// nil if the Object is null or undefined.
+//
+// The given Object is released. The Object values must be released
+// using Free, when no longer in use.
+func DecodeMap[K MapKey, V Value](o Object) map[K]V {
+	defer o.Free()
+	if o.typ != TypeObject {
+		return nil
+	}
+
+	keys, values := o.GetIndex(0), o.GetIndex(1)
+	defer keys.Free()
+	defer values.Free()
+
+	n := int(keys.Length())
+	m := make(map[K]V, n)
+	for i := 0; i < n; i++ {
+		m[decodeValue[K](keys.GetIndex(i))] = decodeValue[V](values.GetIndex(i))
+	}
+	return m
+}
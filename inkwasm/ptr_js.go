@@ -0,0 +1,14 @@
+package inkwasm
+
+// DecodePtr returns a pointer to the value of the given Object, or nil
+// if the Object is null or undefined. The generated code uses it for
+// pointer results, such as *string.
+//
+// The given Object is released, unless T is Object.
+func DecodePtr[T Value](o Object) *T {
+	if o.typ == TypeNull || o.typ == TypeUndefined {
+		return nil
+	}
+	v := decodeValue[T](o)
+	return &v
+}
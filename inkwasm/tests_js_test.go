@@ -2,12 +2,16 @@ package inkwasm
 
 import (
 	"bytes"
+	"context"
 	"math"
+	"math/big"
 	"math/rand"
 	"strconv"
+	"strings"
 	"syscall/js"
 	"testing"
-	_ "unsafe"
+	"time"
+	"unsafe"
 )
 
 func testInvoke(t *testing.T, obj Object, args ...interface{}) {
@@ -62,6 +66,122 @@ func TestExportNested(t *testing.T) {
 	}
 }
 
+//inkwasm:export
+type TestExportRect struct {
+	_      uint64
+	X      float64          `js:"x"`
+	Y      float32          `js:"y"`
+	Width  int32            `js:"width"`
+	Valid  bool             `js:"valid"`
+	Inside TestExportStruct `js:"nested"`
+	Sides  [2]int16         `js:"sides"`
+}
+
+//inkwasm:func globalThis.TestExportedResult
+func gen_TestExportedResult(id int32) TestExportRect
+
+//inkwasm:func globalThis.TestExportedResult
+func gen_TestExportedResultNull(id int32) (TestExportRect, bool)
+
+func TestExportResult(t *testing.T) {
+	r := gen_TestExportedResult(42)
+	if r != (TestExportRect{X: 1.5, Y: -2.5, Width: 100, Valid: true, Inside: TestExportStruct{ID: 42, X: -1}, Sides: [2]int16{3, 4}}) {
+		t.Errorf("exported struct result fail, receives %+v", r)
+	}
+	// Go expects true as 1, other values break comparisons of memory.
+	if v := *(*uint8)(unsafe.Pointer(&r.Valid)); v != 1 {
+		t.Errorf("exported struct bool result fail, receives %d", v)
+	}
+	if r, ok := gen_TestExportedResultNull(0); !ok || r != (TestExportRect{}) {
+		t.Errorf("exported struct null result fail, receives %+v", r)
+	}
+}
+
+//inkwasm:export
+type TestExportPacked struct {
+	A int8  `js:"a"`
+	B int16 `js:"b"`
+	C int8  `js:"c"`
+}
+
+//inkwasm:func globalThis.TestExportedPacked
+func gen_TestExportedPacked(x bool, p TestExportPacked, y int8) TestExportPacked
+
+func TestExportWithoutHeader(t *testing.T) {
+	r := gen_TestExportedPacked(true, TestExportPacked{A: 1, B: -300, C: 3}, 4)
+	if r != (TestExportPacked{A: 5, B: -600, C: 3}) {
+		t.Errorf("exported struct without header fail, receives %+v", r)
+	}
+}
+
+//inkwasm:export
+type TestExportStruct3 struct {
+	Nested [3]TestExportStruct `js:"nested"`
+	Next   *TestExportStruct   `js:"next"`
+}
+
+//inkwasm:func globalThis.TestExportedArray
+func gen_TestExportedArray(TestExportStruct3, int32) int32
+
+//inkwasm:func globalThis.TestExportedNext
+func gen_TestExportedNext(TestExportStruct3) int32
+
+//inkwasm:func globalThis.TestExportedSlice
+func gen_TestExportedSlice(v []TestExportStruct, p *TestExportStruct) int32
+
+//inkwasm:func globalThis.TestExportedSwap
+func gen_TestExportedSwap(v [2]TestExportStruct) [2]TestExportStruct
+
+func TestExportArray(t *testing.T) {
+	x := TestExportStruct3{Nested: [3]TestExportStruct{{ID: 1}, {ID: 2}, {ID: 3}}}
+	if xx := gen_TestExportedArray(x, 6); xx != 6 {
+		t.Errorf("exported array of structs fail, receives %v", xx)
+	}
+	if xx := gen_TestExportedNext(x); xx != -1 {
+		t.Errorf("exported nil pointer fail, receives %v", xx)
+	}
+	x.Next = &TestExportStruct{ID: 42}
+	if xx := gen_TestExportedNext(x); xx != 42 {
+		t.Errorf("exported pointer fail, receives %v", xx)
+	}
+	if xx := gen_TestExportedSlice(x.Nested[:], &x.Nested[2]); xx != 9 {
+		t.Errorf("exported slice of structs fail, receives %v", xx)
+	}
+	if xx := gen_TestExportedSlice(nil, nil); xx != 0 {
+		t.Errorf("exported nil slice of structs fail, receives %v", xx)
+	}
+	r := gen_TestExportedSwap([2]TestExportStruct{{ID: 1, X: 2}, {ID: 3, X: 4}})
+	if r != [2]TestExportStruct{{ID: 3, X: 4}, {ID: 1, X: 2}} {
+		t.Errorf("exported array of structs result fail, receives %v", r)
+	}
+}
+
+//inkwasm:export
+type TestExportOptions struct {
+	Alpha            bool  `js:"alpha,omitempty"`
+	Antialias        bool  `js:"antialias,omitempty"`
+	Secret           int32 `js:"-"`
+	Dash             int32 `js:"-,"`
+	TestExportStruct `js:",inline"`
+}
+
+//inkwasm:func globalThis.TestExportedOptions
+func gen_TestExportedOptions(v TestExportOptions) string
+
+//inkwasm:func globalThis.TestExportedOptionsResult
+func gen_TestExportedOptionsResult() TestExportOptions
+
+func TestExportTagOptions(t *testing.T) {
+	x := TestExportOptions{Antialias: true, Secret: 1, Dash: 2, TestExportStruct: TestExportStruct{ID: 3, X: 4}}
+	if r := gen_TestExportedOptions(x); r != `{"-":2,"antialias":true,"id":3,"x":4}` {
+		t.Errorf("exported tag options fail, receives %v", r)
+	}
+	r := gen_TestExportedOptionsResult()
+	if r != (TestExportOptions{Alpha: true, Dash: 5, TestExportStruct: TestExportStruct{ID: 6, X: 7}}) {
+		t.Errorf("exported tag options result fail, receives %+v", r)
+	}
+}
+
 //inkwasm:func globalThis.TestAlignment
 func gen_TestAlignment(bool, int16) int16
 
@@ -140,6 +260,520 @@ func TestNonExistentFunction(t *testing.T) {
 	}
 }
 
+//inkwasm:func nonExistentFunction catch=silent
+func gen_nonExistentFunctionSilent(s string) (Object, bool)
+
+//inkwasm:func nonExistentFunction catch=silent
+func gen_nonExistentFunctionVoid(s string)
+
+func TestNonExistentFunctionSilent(t *testing.T) {
+	_, ok := gen_nonExistentFunctionSilent("Hello, 世界")
+	if ok {
+		t.Error("non existent function should return false")
+	}
+	gen_nonExistentFunctionVoid("Hello, 世界")
+}
+
+//inkwasm:func globalThis.TestNullable nullable
+func gen_TestNullable(b bool) (string, bool)
+
+//inkwasm:func nonExistentFunction nullable catch=silent
+func gen_TestNullableCatch() (string, bool)
+
+//inkwasm:func globalThis.TestNullable
+func gen_TestNullablePtr(b bool) *string
+
+//inkwasm:get globalThis.TestNullableStorage
+func gen_TestNullableItem(k string) *string
+
+//inkwasm:func globalThis.TestNullableNumber
+func gen_TestNullableNumber(n int32) *float64
+
+func TestNullable(t *testing.T) {
+	if s, ok := gen_TestNullable(true); !ok || s != "Hello, 世界" {
+		t.Error("nullable should return the value", s, ok)
+	}
+	if _, ok := gen_TestNullable(false); ok {
+		t.Error("nullable should return false for null")
+	}
+	if _, ok := gen_TestNullableCatch(); ok {
+		t.Error("nullable with catch should return false for exceptions")
+	}
+	if s := gen_TestNullablePtr(true); s == nil || *s != "Hello, 世界" {
+		t.Error("pointer result should return the value", s)
+	}
+	if s := gen_TestNullablePtr(false); s != nil {
+		t.Error("pointer result should be nil for null, got", *s)
+	}
+	if s := gen_TestNullableItem("empty"); s == nil || *s != "" {
+		t.Error("pointer result should return the empty string", s)
+	}
+	if s := gen_TestNullableItem("missing"); s != nil {
+		t.Error("pointer result should be nil for undefined, got", *s)
+	}
+	if f := gen_TestNullableNumber(3); f == nil || *f != 1.5 {
+		t.Error("pointer result should return the number", f)
+	}
+	if f := gen_TestNullableNumber(-1); f != nil {
+		t.Error("pointer result should be nil for undefined, got", *f)
+	}
+}
+
+//inkwasm:func globalThis.TestCopyArgs copy=args
+func gen_TestCopyArgs(b []uint32)
+
+//inkwasm:func globalThis.TestCopyArgsSum
+func gen_TestCopyArgsSum() int
+
+func TestCopyArgs(t *testing.T) {
+	b := []uint32{1, 2, 3, 4, 5}
+	gen_TestCopyArgs(b)
+	b[0] = 100
+	if sum := gen_TestCopyArgsSum(); sum != 15 {
+		t.Error("copy=args should copy the slice, got", sum)
+	}
+}
+
+//inkwasm:func globalThis.TestTemplate[$0]($1, $2)
+func gen_TestTemplate(method string, a, b int) int
+
+//inkwasm:func globalThis.TestTemplate.sub($1, $0)
+func gen_TestTemplateReversed(b, a int) int
+
+//inkwasm:get globalThis.TestTemplateObject[$0][$1]
+func gen_TestTemplateGet(a, b string) string
+
+//inkwasm:set globalThis.TestTemplateObject[$0].c
+func gen_TestTemplateSet(a string, v string)
+
+func TestTemplate(t *testing.T) {
+	if r := gen_TestTemplate("add", 5, 2); r != 7 {
+		t.Error("template add error, got", r)
+	}
+	if r := gen_TestTemplate("sub", 5, 2); r != 3 {
+		t.Error("template sub error, got", r)
+	}
+	if r := gen_TestTemplateReversed(2, 5); r != 3 {
+		t.Error("template with reversed arguments error, got", r)
+	}
+	if r := gen_TestTemplateGet("a", "b"); r != "Hello, 世界" {
+		t.Error("template get error, got", r)
+	}
+	gen_TestTemplateSet("a", "Hello")
+	if r := gen_TestTemplateGet("a", "c"); r != "Hello" {
+		t.Error("template set error, got", r)
+	}
+}
+
+type testCounter struct{ Object }
+
+type testCounterNamed Object
+
+//inkwasm:new globalThis.TestMethodCounter
+func gen_TestMethodCounter(v int) testCounter
+
+//inkwasm:func .add
+func (c testCounter) Add(n int) int
+
+//inkwasm:get .value
+func (c testCounter) Value() int
+
+//inkwasm:set .value
+func (c testCounter) SetValue(v int)
+
+//inkwasm:func $this.add($0 * 2)
+func (c testCounter) AddTwice(n int) int
+
+//inkwasm:func globalThis.TestMethodDouble
+func (c testCounter) Double() int
+
+//inkwasm:get .value
+func (c testCounterNamed) Value() int
+
+func TestMethod(t *testing.T) {
+	c := gen_TestMethodCounter(1)
+	defer c.Free()
+
+	if r := c.Add(2); r != 3 {
+		t.Error("method func error, got", r)
+	}
+	c.SetValue(10)
+	if r := c.Value(); r != 10 {
+		t.Error("method get/set error, got", r)
+	}
+	if r := c.AddTwice(5); r != 20 {
+		t.Error("method template error, got", r)
+	}
+	if r := c.Double(); r != 40 {
+		t.Error("method call error, got", r)
+	}
+	if r := testCounterNamed(c.Object).Value(); r != 20 {
+		t.Error("method of named Object error, got", r)
+	}
+}
+
+//inkwasm:bind globalThis.TestBind
+type testStorage interface {
+	GetItem(k string) string
+	SetItem(k, v string)
+	Length() int // js:"length" hint:"get"
+}
+
+//inkwasm:bind .
+type testCounterInterface interface {
+	Add(n int) int
+	Value() int // hint:"get"
+}
+
+func TestBind(t *testing.T) {
+	var s testStorage = testStorageJS{}
+	s.SetItem("a", "Hello")
+	s.SetItem("b", "世界")
+	if r := s.GetItem("b"); r != "世界" {
+		t.Error("bind func error, got", r)
+	}
+	if r := s.Length(); r != 2 {
+		t.Error("bind get error, got", r)
+	}
+
+	c := gen_TestMethodCounter(1)
+	defer c.Free()
+
+	var i testCounterInterface = testCounterInterfaceJS{Object: c.Object}
+	if r := i.Add(2); r != 3 {
+		t.Error("bind of object error, got", r)
+	}
+	if r := i.Value(); r != 3 {
+		t.Error("bind get of object error, got", r)
+	}
+}
+
+//inkwasm:func globalThis.TestVariadic
+func gen_TestVariadic(prefix string, v ...string) string
+
+//inkwasm:func globalThis.TestVariadicCount
+func gen_TestVariadicCount(v ...interface{}) int
+
+//inkwasm:func globalThis.TestVariadicSum
+func gen_TestVariadicSum(v ...int) int
+
+//inkwasm:func globalThis.TestVariadicCount
+func gen_TestVariadicObjects(v ...Object) int
+
+//inkwasm:func globalThis.TestVariadic($0, ...$1)
+func gen_TestVariadicTemplate(prefix string, v ...string) string
+
+func TestVariadic(t *testing.T) {
+	if r := gen_TestVariadic("a", "b", "c"); r != "a:b,c" {
+		t.Error("variadic string error, got", r)
+	}
+	if r := gen_TestVariadic("a"); r != "a:" {
+		t.Error("variadic without arguments error, got", r)
+	}
+	if r := gen_TestVariadicCount(1, "b", true); r != 3 {
+		t.Error("variadic interface error, got", r)
+	}
+	if r := gen_TestVariadicSum([]int{1, 2, 3}...); r != 6 {
+		t.Error("variadic int error, got", r)
+	}
+	if r := gen_TestVariadicObjects(Global(), Global()); r != 2 {
+		t.Error("variadic Object error, got", r)
+	}
+	if r := gen_TestVariadicTemplate("a", "b"); r != "a:b" {
+		t.Error("variadic template error, got", r)
+	}
+}
+
+//inkwasm:func globalThis.TestOptional
+func gen_TestOptional(a int, b *int, c *string) string
+
+func TestOptional(t *testing.T) {
+	b, c := 2, "Hello"
+	if r := gen_TestOptional(1, nil, nil); r != `[1,1,null,null]` {
+		t.Error("optional omitted error, got", r)
+	}
+	if r := gen_TestOptional(1, &b, nil); r != `[2,1,2,null]` {
+		t.Error("optional trailing error, got", r)
+	}
+	if r := gen_TestOptional(1, nil, &c); r != `[3,1,null,"Hello"]` {
+		t.Error("optional undefined error, got", r)
+	}
+}
+
+//inkwasm:func globalThis.TestComplex
+func gen_TestComplex(c complex128) complex128
+
+//inkwasm:func globalThis.TestComplex
+func gen_TestComplex64(c complex64) complex64
+
+//inkwasm:func globalThis.TestComplexSwap
+func gen_TestComplexSlice(c []complex128) []complex128
+
+//inkwasm:func globalThis.TestComplexSwap
+func gen_TestComplexArray(c [2]complex64) [2]complex64
+
+//inkwasm:func globalThis.TestComplexInterleaved complex=interleaved
+func gen_TestComplexInterleaved(c []complex64) []complex64
+
+//inkwasm:func globalThis.TestComplexInterleaved complex=interleaved
+func gen_TestComplexArrayInterleaved(c [2]complex128) [2]complex128
+
+func TestComplex(t *testing.T) {
+	if r := gen_TestComplex(complex(1, 2)); r != complex(2, 3) {
+		t.Error("complex128 error, got", r)
+	}
+	if r := gen_TestComplex64(complex(1.5, -2)); r != complex(3, -1) {
+		t.Error("complex64 error, got", r)
+	}
+	if r := gen_TestComplexSlice([]complex128{complex(1, 2), complex(3, 4)}); len(r) != 2 || r[0] != complex(2, 1) || r[1] != complex(4, 3) {
+		t.Error("complex slice error, got", r)
+	}
+	if r := gen_TestComplexArray([2]complex64{complex(1, 2), complex(3, 4)}); r != [2]complex64{complex(2, 1), complex(4, 3)} {
+		t.Error("complex array error, got", r)
+	}
+	if r := gen_TestComplexInterleaved([]complex64{complex(1, 2), complex(3, 4)}); len(r) != 2 || r[0] != complex(2, 4) || r[1] != complex(6, 8) {
+		t.Error("complex interleaved slice error, got", r)
+	}
+	if r := gen_TestComplexArrayInterleaved([2]complex128{complex(1, 2), complex(3, 4)}); r != [2]complex128{complex(2, 4), complex(6, 8)} {
+		t.Error("complex interleaved array error, got", r)
+	}
+}
+
+//inkwasm:func globalThis.TestBigInt
+func gen_TestBigInt(v *big.Int) *big.Int
+
+//inkwasm:func globalThis.TestBigInt
+func gen_TestBigIntValue(v big.Int) big.Int
+
+//inkwasm:get globalThis.TestBigIntNull
+func gen_TestBigIntNull() *big.Int
+
+//inkwasm:get globalThis.TestBigIntNumber
+func gen_TestBigIntNumber() *big.Int
+
+//inkwasm:get globalThis.TestBigIntFraction
+func gen_TestBigIntFraction() *big.Int
+
+//inkwasm:get globalThis.TestBigIntFraction
+func gen_TestBigIntFractionValue() big.Int
+
+//inkwasm:get globalThis.TestBigIntString
+func gen_TestBigIntString() *big.Int
+
+func TestBigInt(t *testing.T) {
+	v, _ := new(big.Int).SetString("-1267650600228229401496703205381", 10)
+	want := new(big.Int).Mul(v, big.NewInt(2))
+	if r := gen_TestBigInt(v); r == nil || r.Cmp(want) != 0 {
+		t.Error("big.Int pointer error, got", r)
+	}
+	if r := gen_TestBigInt(new(big.Int)); r == nil || r.Sign() != 0 {
+		t.Error("big.Int zero error, got", r)
+	}
+	if r := gen_TestBigIntValue(*big.NewInt(21)); r.Cmp(big.NewInt(42)) != 0 {
+		t.Error("big.Int value error, got", r.String())
+	}
+	if r := gen_TestBigIntNull(); r != nil {
+		t.Error("big.Int null error, got", r)
+	}
+	if r := gen_TestBigIntNumber(); r == nil || r.Int64() != 1024 {
+		t.Error("big.Int of Number error, got", r)
+	}
+	if r := gen_TestBigIntFraction(); r != nil {
+		t.Error("big.Int of fractional Number error, got", r)
+	}
+	if r := gen_TestBigIntFractionValue(); r.Sign() != 0 {
+		t.Error("big.Int value of fractional Number error, got", r.String())
+	}
+	if r := gen_TestBigIntString(); r != nil {
+		t.Error("big.Int of String error, got", r)
+	}
+
+	obj := Global().GetProperty("TestBigIntObject")
+	defer obj.Free()
+	if r, err := obj.BigInt(); err != nil || r.String() != "-18446744073709551617" {
+		t.Error("Object.BigInt error, got", r, err)
+	}
+	num := Global().GetProperty("TestBigIntNumber")
+	defer num.Free()
+	if r, err := num.BigInt(); err != nil || r.Int64() != 1024 {
+		t.Error("Object.BigInt of Number error, got", r, err)
+	}
+	plain := Global().GetProperty("TestBigIntPlain")
+	defer plain.Free()
+	if r, err := plain.BigInt(); err != ErrInvalidType {
+		t.Error("Object.BigInt of Object should fail, got", r, err)
+	}
+}
+
+//inkwasm:func globalThis.TestMapKeys
+func gen_TestMapKeys(m map[string]int) string
+
+//inkwasm:func globalThis.TestMapKeys map=map
+func gen_TestMapKeysMap(m map[int]interface{}) string
+
+//inkwasm:func globalThis.TestMapDouble
+func gen_TestMapDouble(m map[string]float64) map[string]float64
+
+//inkwasm:func globalThis.TestMapNumbers
+func gen_TestMapNumbers() map[int]string
+
+//inkwasm:get globalThis.TestMapNull
+func gen_TestMapNull() map[string]string
+
+func TestMap(t *testing.T) {
+	if r := gen_TestMapKeys(map[string]int{"b": 2, "a": 1}); r != "object:a=1,b=2" {
+		t.Error("map object error, got", r)
+	}
+	if r := gen_TestMapKeys(nil); r != "object:" {
+		t.Error("map nil error, got", r)
+	}
+	if r := gen_TestMapKeysMap(map[int]interface{}{1: "a", 2: true}); r != "map:1=a,2=true" {
+		t.Error("map Map error, got", r)
+	}
+
+	r := gen_TestMapDouble(map[string]float64{"a": 1.5, "b": -2})
+	if len(r) != 2 || r["a"] != 3 || r["b"] != -4 {
+		t.Error("map result error, got", r)
+	}
+	if r := gen_TestMapNumbers(); len(r) != 2 || r[1] != "a" || r[2] != "b" {
+		t.Error("map Map result error, got", r)
+	}
+	if r := gen_TestMapNull(); r != nil {
+		t.Error("map null error, got", r)
+	}
+}
+
+//inkwasm:func globalThis.TestChanReceive
+func gen_TestChanReceive(n int) <-chan string
+
+//inkwasm:func globalThis.TestChanDouble
+func gen_TestChanDouble(c <-chan int) <-chan int
+
+//inkwasm:func globalThis.TestChanStream
+func gen_TestChanStream() Object
+
+//inkwasm:get globalThis.TestChanCancelled
+func gen_TestChanCancelled() bool
+
+//inkwasm:func globalThis.TestChanPending
+func gen_TestChanPending() Object
+
+//inkwasm:get globalThis.TestChanPendingReturned
+func gen_TestChanPendingReturned() bool
+
+func TestChan(t *testing.T) {
+	var r []string
+	for v := range gen_TestChanReceive(3) {
+		r = append(r, v)
+	}
+	if strings.Join(r, ",") != "a0,a1,a2" {
+		t.Error("chan result error, got", r)
+	}
+
+	in := make(chan int)
+	out := gen_TestChanDouble(in)
+	go func() {
+		for i := 1; i <= 3; i++ {
+			in <- i
+		}
+		close(in)
+	}()
+	sum := 0
+	for v := range out {
+		sum += v
+	}
+	if sum != 12 {
+		t.Error("chan argument error, got", sum)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := ReceiveChan[Object](ctx, gen_TestChanStream())
+	v := <-stream
+	if s := v.MustString(); s != "tick" {
+		t.Error("chan stream error, got", s)
+	}
+	v.Free()
+	cancel()
+	for v := range stream {
+		v.Free()
+	}
+	for i := 0; i < 100 && !gen_TestChanCancelled(); i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if !gen_TestChanCancelled() {
+		t.Error("chan cancel error, the iterable wasn't cancelled")
+	}
+
+	// The channel is closed without waiting for the next value.
+	ctx, cancel = context.WithCancel(context.Background())
+	pending := ReceiveChan[string](ctx, gen_TestChanPending())
+	cancel()
+	select {
+	case _, ok := <-pending:
+		if ok {
+			t.Error("chan pending error, unexpected value")
+		}
+	case <-time.After(time.Second):
+		t.Error("chan pending error, the channel wasn't closed")
+	}
+	for i := 0; i < 100 && !gen_TestChanPendingReturned(); i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if !gen_TestChanPendingReturned() {
+		t.Error("chan pending error, the iterable wasn't returned")
+	}
+}
+
+//inkwasm:func globalThis.TestOutDouble out=v
+func gen_TestOutDouble(x int32, v *int32)
+
+//inkwasm:func globalThis.TestOutArray out=v
+func gen_TestOutArray(v *[4]float32)
+
+//inkwasm:func globalThis.TestOutStruct out=v
+func gen_TestOutStruct(v *TestExportStruct, id int32)
+
+//inkwasm:func ($0 * 3) out=v
+func gen_TestOutTemplate(x int32, v *int32)
+
+//inkwasm:func globalThis.TestInOut inout=a,s
+func gen_TestInOut(a *[4]float32, s *TestExportStruct)
+
+func TestOutArguments(t *testing.T) {
+	var x int32
+	gen_TestOutDouble(21, &x)
+	if x != 42 {
+		t.Error("out argument error, got", x)
+	}
+	gen_TestOutDouble(21, nil)
+
+	var a [4]float32
+	gen_TestOutArray(&a)
+	if a != [4]float32{1, 2, 3, 4} {
+		t.Error("out array error, got", a)
+	}
+
+	s := TestExportStruct{ID: 1, X: 2}
+	gen_TestOutStruct(&s, 7)
+	if s != (TestExportStruct{ID: 7, X: 8}) {
+		t.Error("out struct error, got", s)
+	}
+
+	gen_TestOutTemplate(5, &x)
+	if x != 15 {
+		t.Error("out template error, got", x)
+	}
+
+	gen_TestInOut(&a, &s)
+	if a != [4]float32{2, 4, 6, 8} || s != (TestExportStruct{ID: 8, X: 9}) {
+		t.Error("inout error, got", a, s)
+	}
+	gen_TestInOut(&a, nil)
+	if a != [4]float32{4, 8, 12, 16} {
+		t.Error("inout nil error, got", a)
+	}
+}
+
 //inkwasm:func globalThis.TestObjectType_String
 func gen_TestObjectType_String(s string) bool
 
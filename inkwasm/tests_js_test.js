@@ -21,6 +21,43 @@
         }
         return sum
     }
+    globalThis.TestExportedResult = function (id) {
+        if (id === 0) {
+            return null
+        }
+        return {x: 1.5, y: -2.5, width: 100, valid: true, nested: {id: id, x: -1}, sides: [3, 4]}
+    }
+    globalThis.TestExportedPacked = function (x, p, y) {
+        if (!x) {
+            return null
+        }
+        return {a: p.a + y, b: p.b * 2, c: p.c}
+    }
+    globalThis.TestExportedNext = function (e) {
+        if (e.next === null) {
+            return -1
+        }
+        return e.next.id
+    }
+    globalThis.TestExportedSlice = function (v, p) {
+        if (v === null) {
+            return 0
+        }
+        let sum = p.id
+        for (let i = 0; i < v.length; i++) {
+            sum += v[i].id
+        }
+        return sum
+    }
+    globalThis.TestExportedSwap = function (v) {
+        return [v[1], v[0]]
+    }
+    globalThis.TestExportedOptions = function (v) {
+        return JSON.stringify(v, Object.keys(v).sort())
+    }
+    globalThis.TestExportedOptionsResult = function () {
+        return {alpha: true, "-": 5, Secret: 8, id: 6, x: 7}
+    }
     globalThis.TestAlignment = function (b, v) {
         if (b) {
             return v
@@ -79,4 +116,161 @@
         }
         return sum
     }
-})();
\ No newline at end of file
+    globalThis.TestNullable = function (e) {
+        if (e) {
+            return "Hello, 世界"
+        }
+        return null
+    }
+    globalThis.TestNullableStorage = {"empty": ""}
+    globalThis.TestNullableNumber = function (n) {
+        if (n < 0) {
+            return undefined
+        }
+        return n / 2
+    }
+    globalThis.TestCopyArgs = function (e) {
+        globalThis.TestCopyArgsStored = e
+    }
+    globalThis.TestCopyArgsSum = function () {
+        return globalThis.TestSumFromArray(globalThis.TestCopyArgsStored)
+    }
+    globalThis.TestTemplate = {
+        add: function (a, b) {
+            return a + b
+        },
+        sub: function (a, b) {
+            return a - b
+        },
+    }
+    globalThis.TestTemplateObject = {
+        a: {b: "Hello, 世界"},
+    }
+    globalThis.TestMethodCounter = function (v) {
+        this.value = v
+        this.add = function (n) {
+            this.value += n
+            return this.value
+        }
+    }
+    globalThis.TestMethodDouble = function () {
+        return this.value * 2
+    }
+    globalThis.TestVariadic = function (prefix, ...v) {
+        return prefix + ":" + v.join(",")
+    }
+    globalThis.TestVariadicCount = function () {
+        return arguments.length
+    }
+    globalThis.TestVariadicSum = function (...v) {
+        return v.reduce((a, b) => a + b, 0)
+    }
+    globalThis.TestOptional = function (a, b, c) {
+        return JSON.stringify([arguments.length, a, b, c])
+    }
+    globalThis.TestComplex = function (c) {
+        return {re: c.re * 2, im: c.im + 1}
+    }
+    globalThis.TestComplexSwap = function (v) {
+        return v.map((c) => ({re: c.im, im: c.re}))
+    }
+    globalThis.TestComplexInterleaved = function (v) {
+        if (!ArrayBuffer.isView(v)) {
+            throw new Error("expected a typed array")
+        }
+        return v.map((x) => x * 2)
+    }
+    globalThis.TestBigInt = function (v) {
+        return v * 2n
+    }
+    globalThis.TestBigIntNull = null
+    globalThis.TestBigIntObject = -18446744073709551617n
+    globalThis.TestBigIntNumber = 1024
+    globalThis.TestBigIntPlain = {length: 3}
+    globalThis.TestBigIntFraction = 1.5
+    globalThis.TestBigIntString = "abc"
+    globalThis.TestMapKeys = function (m) {
+        let entries = m instanceof Map ? Array.from(m.entries()) : Object.entries(m)
+        entries.sort((a, b) => a[0] < b[0] ? -1 : 1)
+        return (m instanceof Map ? "map:" : "object:") + entries.map((e) => e[0] + "=" + e[1]).join(",")
+    }
+    globalThis.TestMapDouble = function (m) {
+        let result = {}
+        for (const k in m) {
+            result[k] = m[k] * 2
+        }
+        return result
+    }
+    globalThis.TestMapNumbers = function () {
+        return new Map([[1, "a"], [2, "b"]])
+    }
+    globalThis.TestMapNull = null
+    globalThis.TestChanReceive = async function* (n) {
+        for (let i = 0; i < n; i++) {
+            yield "a" + i
+        }
+    }
+    globalThis.TestChanDouble = async function* (it) {
+        for await (const v of it) {
+            yield v * 2
+        }
+    }
+    globalThis.TestChanCancelled = false
+    globalThis.TestChanStream = function () {
+        return new ReadableStream({
+            pull: function (controller) {
+                return new Promise((resolve) => setTimeout(resolve, 1)).then(() => controller.enqueue("tick"))
+            },
+            cancel: function () {
+                globalThis.TestChanCancelled = true
+            },
+        })
+    }
+    globalThis.TestChanPendingReturned = false
+    globalThis.TestChanPending = function () {
+        // The next value never arrives.
+        return {
+            [Symbol.asyncIterator]: function () {
+                return this
+            },
+            next: function () {
+                return new Promise(() => {})
+            },
+            return: function () {
+                globalThis.TestChanPendingReturned = true
+                return Promise.resolve({done: true, value: undefined})
+            },
+        }
+    }
+    globalThis.TestOutDouble = function (x) {
+        return x * 2
+    }
+    globalThis.TestOutArray = function () {
+        return new Float32Array([1, 2, 3, 4])
+    }
+    globalThis.TestOutStruct = function (id) {
+        return {id: id, x: id + 1}
+    }
+    globalThis.TestInOut = function (a, s) {
+        for (let i = 0; i < a.length; i++) {
+            a[i] *= 2
+        }
+        if (s !== undefined) {
+            s.id++
+            s.x++
+        }
+    }
+    globalThis.TestBind = {
+        items: {},
+        length: 0,
+        getItem: function (k) {
+            return this.items[k]
+        },
+        setItem: function (k, v) {
+            if (!(k in this.items)) {
+                this.length++
+            }
+            this.items[k] = v
+        },
+    }
+})();
@@ -44,6 +44,8 @@ package inkwasm
 
 import (
 	"errors"
+	"math"
+	"math/big"
 	"unsafe"
 )
 
@@ -303,6 +305,54 @@ func (o Object) MustBytes(buf []byte) []byte {
 //inkwasm:func globalThis.inkwasm.Internal.Copy
 func copyBytes(o Object, buf []byte)
 
+// BigInt return the value from the current Object as
+// big.Int.
+//
+// It will return error if the current Object isn't TypeBigInt
+// or an integer TypeNumber.
+func (o Object) BigInt() (*big.Int, error) {
+	switch o.typ {
+	case TypeNumber:
+		f, _ := o.Float()
+		if f != math.Trunc(f) || math.IsInf(f, 0) {
+			return nil, ErrInvalidType
+		}
+		r, _ := big.NewFloat(f).Int(nil)
+		return r, nil
+	case TypeBigInt:
+		src := encodeBigInt(o)
+		defer src.Free()
+		return decodeBigInt(src), nil
+	default:
+		return nil, ErrInvalidType
+	}
+}
+
+// MustBigInt is a wrapper to BigInt, but suppress errors.
+func (o Object) MustBigInt() *big.Int {
+	r, _ := o.BigInt()
+	return r
+}
+
+// decodeBigInt decodes the sign, as the first byte, and the
+// magnitude, as big-endian.
+func decodeBigInt(o Object) *big.Int {
+	buf := make([]byte, o.len, o.len)
+	copyBytes(o, buf)
+	r := new(big.Int)
+	if len(buf) == 0 {
+		return r
+	}
+	r.SetBytes(buf[1:])
+	if buf[0] == 1 {
+		r.Neg(r)
+	}
+	return r
+}
+
+//inkwasm:func globalThis.inkwasm.Internal.EncodeBigInt
+func encodeBigInt(o Object) (_ Object)
+
 // Length returns the length of the current object,
 // when the object is string or array.
 //
@@ -11,6 +11,7 @@
 
     let Objects = [];
     let ObjectsUnused = [];
+    let IteratorsReturned = new WeakSet();
 
     let ObjectTypes = {
         TypeUndefined: 0,
@@ -75,6 +76,14 @@
             return new Object(args)
         },
         Copy: function (o, slice) {
+            if (slice === null) {
+                // Empty slices are null, there's nothing to copy.
+                return
+            }
+            if (ArrayBuffer.isView(o) && !(o instanceof Uint8Array)) {
+                // The bytes are copied, such as Float32Array.
+                o = new Uint8Array(o.buffer, o.byteOffset, o.byteLength)
+            }
             if (o instanceof ArrayBuffer) {
                 switch (true) {
                     case slice instanceof Int8Array:
@@ -112,9 +121,62 @@
                 slice.set(o)
             }
         },
+        Trim: function (args) {
+            let len = args.length
+            while (len > 0 && args[len - 1] === undefined) {
+                len--
+            }
+            args.length = len
+            return args
+        },
+        Interleave: function (v, T) {
+            if (v === null || v === undefined || ArrayBuffer.isView(v)) {
+                return v
+            }
+            let result = new T(v.length * 2)
+            for (let i = 0; i < v.length; i++) {
+                if (typeof v[i] === "number") {
+                    result[i * 2] = v[i]
+                } else {
+                    result[i * 2] = v[i].re
+                    result[i * 2 + 1] = v[i].im
+                }
+            }
+            return result
+        },
+        OmitEmpty: function (o, k, v) {
+            // Same as omitempty of encoding/json.
+            switch (true) {
+                case v === undefined || v === null || v === false || v === 0 || v === 0n || v === "":
+                    return
+                case (Array.isArray(v) || ArrayBuffer.isView(v)) && v.length === 0:
+                    return
+            }
+            o[k] = v
+        },
+        Clone: function (o) {
+            if (ArrayBuffer.isView(o)) {
+                return o.slice()
+            }
+            return o
+        },
         EncodeString: function (o) {
             return StringEncoder.encode(o);
         },
+        EncodeBigInt: function (o) {
+            // The first byte is the sign, followed by the magnitude.
+            let neg = o < 0n
+            let hex = (neg ? -o : o).toString(16)
+            if (hex.length % 2 !== 0) {
+                hex = "0" + hex
+            }
+            let result = new Uint8Array(1 + hex.length / 2)
+            result[0] = neg ? 1 : 0
+            for (let i = 0; i < hex.length / 2; i++) {
+                result[i + 1] = parseInt(hex.slice(i * 2, (i + 1) * 2), 16)
+            }
+            return result
+        },
         InstanceOf: function (o, v) {
             return o instanceof v
         },
@@ -123,6 +185,91 @@
         },
         StrictEqual: function (o, v) {
             return o === v
+        },
+        Iterator: function (o) {
+            if (o === null || o === undefined) {
+                return {next: () => ({done: true, value: undefined})}
+            }
+            if (o[Symbol.asyncIterator] !== undefined) {
+                return o[Symbol.asyncIterator]()
+            }
+            if (typeof o.getReader === "function") {
+                // ReadableStream, without async iterator support.
+                let reader = o.getReader()
+                return {
+                    next: () => reader.read(),
+                    return: () => reader.cancel(),
+                }
+            }
+            if (o[Symbol.iterator] !== undefined) {
+                return o[Symbol.iterator]()
+            }
+            return o
+        },
+        Next: function (it, fn) {
+            // The result is set before calling fn, rejected iterators are done.
+            // The fn isn't called once the iterator is returned, see Return.
+            let result = {done: true, value: undefined}
+            Promise.resolve().then(() => it.next()).then((r) => {
+                result.done = r.done
+                result.value = r.value
+            }, (e) => {
+                console.log(e)
+            }).finally(() => {
+                if (!IteratorsReturned.has(it)) {
+                    fn()
+                }
+            })
+            return result
+        },
+        Return: function (it) {
+            if (Object(it) === it) {
+                IteratorsReturned.add(it)
+            }
+            if (typeof it.return === "function") {
+                Promise.resolve().then(() => it.return()).catch((e) => console.log(e))
+            }
+        },
+        Iterable: function (fn) {
+            // The fn(false) requests one value, and fn(true) cancels. The
+            // values are given using the push function, in order.
+            let pending = []
+            let done = false
+            let finish = function () {
+                done = true
+                pending.splice(0).forEach((resolve) => resolve({done: true, value: undefined}))
+            }
+            let iterable = {
+                [Symbol.asyncIterator]: function () {
+                    return this
+                },
+                next: function () {
+                    if (done) {
+                        return Promise.resolve({done: true, value: undefined})
+                    }
+                    return new Promise((resolve) => {
+                        pending.push(resolve)
+                        fn(false)
+                    })
+                },
+                return: function (value) {
+                    if (!done) {
+                        fn(true)
+                        finish()
+                    }
+                    return Promise.resolve({done: true, value: value})
+                },
+            }
+            let push = function (end, value) {
+                if (end) {
+                    return finish()
+                }
+                let resolve = pending.shift()
+                if (resolve !== undefined) {
+                    resolve({done: false, value: globalThis.inkwasm.Internal.Clone(value)})
+                }
+            }
+            return [iterable, push]
         }
     })
 
@@ -184,6 +331,13 @@
             return globalThis.inkwasm.Load.Uint32(go, sp, offset)
         },
 
+        Complex64: function (go, sp, offset) {
+            return {re: go.mem.getFloat32(sp + offset, true), im: go.mem.getFloat32(sp + offset + 4, true)}
+        },
+        Complex128: function (go, sp, offset) {
+            return {re: go.mem.getFloat64(sp + offset, true), im: go.mem.getFloat64(sp + offset + 8, true)}
+        },
+
         ArrayFloat32: function (go, sp, offset, len) {
             return new Float32Array(go._inst.exports.mem.buffer, sp + offset, len)
         },
@@ -233,6 +387,41 @@
             }
             return result
         },
+        ArrayInt: function (go, sp, offset, len) {
+            let result = new Array(len)
+            for (let i = 0; i < len; i++) {
+                result[i] = globalThis.inkwasm.Load.Int(go, sp, offset + (i * 8))
+            }
+            return result
+        },
+        ArrayUint: function (go, sp, offset, len) {
+            let result = new Array(len)
+            for (let i = 0; i < len; i++) {
+                result[i] = globalThis.inkwasm.Load.Uint(go, sp, offset + (i * 8))
+            }
+            return result
+        },
+        ArrayBool: function (go, sp, offset, len) {
+            let result = new Array(len)
+            for (let i = 0; i < len; i++) {
+                result[i] = globalThis.inkwasm.Load.Bool(go, sp, offset + i)
+            }
+            return result
+        },
+        ArrayString: function (go, sp, offset, len) {
+            let result = new Array(len)
+            for (let i = 0; i < len; i++) {
+                result[i] = globalThis.inkwasm.Load.String(go, sp, offset + (i * 16))
+            }
+            return result
+        },
+        ArrayInkwasmObject: function (go, sp, offset, len) {
+            let result = new Array(len)
+            for (let i = 0; i < len; i++) {
+                result[i] = globalThis.inkwasm.Load.InkwasmObject(go, sp, offset + (i * 16))
+            }
+            return result
+        },
         Interface: function (go, sp, offset) {
             let ptr_rtype = globalThis.inkwasm.Load.UintPtr(go, sp, offset)
             let ptr_data = globalThis.inkwasm.Load.Int(go, sp, offset + 8)
@@ -351,8 +540,29 @@
             }
         },
 
+        ArrayComplex64: function (go, sp, offset, len) {
+            let result = new Array(len)
+            for (let i = 0; i < len; i++) {
+                result[i] = globalThis.inkwasm.Load.Complex64(go, sp, offset + (i * 8))
+            }
+            return result
+        },
+        ArrayComplex128: function (go, sp, offset, len) {
+            let result = new Array(len)
+            for (let i = 0; i < len; i++) {
+                result[i] = globalThis.inkwasm.Load.Complex128(go, sp, offset + (i * 16))
+            }
+            return result
+        },
+        ArrayComplex64Interleaved: function (go, sp, offset, len) {
+            return new Float32Array(go._inst.exports.mem.buffer, sp + offset, len * 2)
+        },
+        ArrayComplex128Interleaved: function (go, sp, offset, len) {
+            return new Float64Array(go._inst.exports.mem.buffer, sp + offset, len * 2)
+        },
+
         Array: function (go, sp, offset, len, f) {
-            return f(go, sp, offset, len).slice(0, len)
+            return f(go, sp, offset, len).slice()
         },
         Slice: function (go, sp, offset, f) {
             let ptr = globalThis.inkwasm.Load.UintPtr(go, sp, offset)
@@ -363,7 +573,39 @@
             return f(go, ptr, 0, len)
         },
         Ptr: function (go, sp, offset, f) {
-            return f(go, globalThis.inkwasm.Load.UintPtr(go, sp, offset), 0)
+            let ptr = globalThis.inkwasm.Load.UintPtr(go, sp, offset)
+            if (ptr === 0) {
+                return undefined
+            }
+            return f(go, ptr, 0)
+        },
+        MapObject: function (go, sp, offset, k, v) {
+            let keys = globalThis.inkwasm.Load.Slice(go, sp, offset, k) || []
+            let values = globalThis.inkwasm.Load.Slice(go, sp, offset + 24, v) || []
+            let result = {}
+            for (let i = 0; i < keys.length; i++) {
+                result[keys[i]] = values[i]
+            }
+            return result
+        },
+        Map: function (go, sp, offset, k, v) {
+            let keys = globalThis.inkwasm.Load.Slice(go, sp, offset, k) || []
+            let values = globalThis.inkwasm.Load.Slice(go, sp, offset + 24, v) || []
+            let result = new Map()
+            for (let i = 0; i < keys.length; i++) {
+                result.set(keys[i], values[i])
+            }
+            return result
+        },
+        ArrayOf: function (f, size) {
+            // The f loads one element, such as exported structs.
+            return function (go, sp, offset, len) {
+                let result = new Array(len)
+                for (let i = 0; i < len; i++) {
+                    result[i] = f(go, sp, offset + (i * size))
+                }
+                return result
+            }
         },
         SliceOf: function (f) {
             return function (go, sp, offset) {
@@ -373,6 +615,9 @@
         BigInt: function (go, sp, offset) {
             const neg = globalThis.inkwasm.Load.Bool(go, sp, offset)
             const abs = globalThis.inkwasm.Load.Slice(go, sp, offset + 8, globalThis.inkwasm.Load.ArrayUint64)
+            if (abs === null) {
+                return 0n
+            }
 
             let length = BigInt(abs.length) - 1n
             let result = BigInt(0)
@@ -420,7 +665,7 @@
 
         Bool: function (go, sp, offset, v) {
             if (v) {
-                globalThis.inkwasm.Set.Uint8(go, sp, offset, 255)
+                globalThis.inkwasm.Set.Uint8(go, sp, offset, 1)
             } else {
                 globalThis.inkwasm.Set.Uint8(go, sp, offset, 0)
             }
@@ -489,11 +734,56 @@
             globalThis.inkwasm.Set.Uint32(go, sp, offset, v)
         },
 
+        Complex64: function (go, sp, offset, v) {
+            if (typeof v === "number") {
+                v = {re: v, im: 0}
+            }
+            go.mem.setFloat32(sp + offset, v.re, true)
+            go.mem.setFloat32(sp + offset + 4, v.im, true)
+        },
+        Complex128: function (go, sp, offset, v) {
+            if (typeof v === "number") {
+                v = {re: v, im: 0}
+            }
+            go.mem.setFloat64(sp + offset, v.re, true)
+            go.mem.setFloat64(sp + offset + 8, v.im, true)
+        },
+
         Slice: function (go, sp, offset, v, m) {
             globalThis.inkwasm.Set.InkwasmObject(go, sp, offset, v)
         },
+        Map: function (go, sp, offset, v) {
+            // The entries are given as [keys, values], see inkwasm.DecodeMap.
+            if (v !== null && v !== undefined) {
+                let entries = v instanceof Map ? Array.from(v.entries()) : Object.entries(v)
+                v = [entries.map((e) => e[0]), entries.map((e) => e[1])]
+            }
+            globalThis.inkwasm.Set.InkwasmObject(go, sp, offset, v)
+        },
+        BigInt: function (go, sp, offset, v) {
+            if (typeof v !== "bigint" && !Number.isInteger(v)) {
+                // Such as null, fractional numbers or strings, which
+                // would throw on BigInt(v), the big.Int is nil.
+                globalThis.inkwasm.Set.InkwasmObject(go, sp, offset, undefined)
+                return
+            }
+            // The BigInt is encoded by Object.BigInt, using EncodeBigInt.
+            globalThis.inkwasm.Set.InkwasmObject(go, sp, offset, BigInt(v))
+        },
+        SliceComplex64: function (go, sp, offset, v, m) {
+            globalThis.inkwasm.Set.Slice(go, sp, offset, globalThis.inkwasm.Internal.Interleave(v, Float32Array), m)
+        },
+        SliceComplex128: function (go, sp, offset, v, m) {
+            globalThis.inkwasm.Set.Slice(go, sp, offset, globalThis.inkwasm.Internal.Interleave(v, Float64Array), m)
+        },
 
+        Zero: function (go, sp, offset, len) {
+            new Uint8Array(go._inst.exports.mem.buffer, sp + offset, len).fill(0)
+        },
         Array: function (go, sp, offset, v, len, m, f) {
+            if (v === null || v === undefined) {
+                return
+            }
             if (v.length < len) {
                 len = v.length
             }
@@ -505,6 +795,13 @@
                 offset += m
             }
         },
+        Ptr: function (go, sp, offset, f) {
+            let ptr = globalThis.inkwasm.Load.UintPtr(go, sp, offset)
+            if (ptr === 0) {
+                return
+            }
+            f(go, ptr)
+        },
 
         /*
         Slice: function (go, sp, offset, v, m) {
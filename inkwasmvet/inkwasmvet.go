@@ -0,0 +1,399 @@
+// Package inkwasmvet defines an Analyzer which checks the //inkwasm:
+// directives, using the same rules of the generator, and the lifetime
+// of inkwasm.Object values.
+//
+// It can be used with go vet, using the cmd/inkwasmvet:
+//
+//	go build -o inkwasmvet github.com/inkeliz/go_inkwasm/cmd/inkwasmvet
+//	GOOS=js GOARCH=wasm go vet -vettool=$(pwd)/inkwasmvet ./...
+package inkwasmvet
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/inkeliz/go_inkwasm/bind"
+	"github.com/inkeliz/go_inkwasm/parser"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const Doc = `check //inkwasm: directives and inkwasm.Object lifetimes
+
+The directives are validated with the same rules of the generator,
+functions with directives must not have a body, since the body is
+provided by the generated assembly.
+
+The inkwasm.Object returned by Call, Invoke, New, Get, GetProperty and
+GetIndex must be freed, using Free. It reports Objects which are never
+freed, unless they escape the function, and Objects used after Free.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "inkwasmvet",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+const objectPath = "github.com/inkeliz/go_inkwasm/inkwasm"
+
+// allocators are the methods of inkwasm.Object which return a new
+// Object, that must be freed.
+var allocators = map[string]bool{
+	"Call":        true,
+	"Invoke":      true,
+	"New":         true,
+	"Get":         true,
+	"GetProperty": true,
+	"GetIndex":    true,
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	checkDirectives(pass)
+
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	inspect.Preorder([]ast.Node{(*ast.FuncDecl)(nil), (*ast.FuncLit)(nil)}, func(n ast.Node) {
+		var body *ast.BlockStmt
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			body = n.Body
+		case *ast.FuncLit:
+			body = n.Body
+		}
+		if body == nil {
+			return
+		}
+		checkLeaks(pass, body)
+		checkUseAfterFree(pass, body)
+	})
+
+	return nil, nil
+}
+
+// checkDirectives reports the problems of the directives, the same
+// problems reported by the generator.
+func checkDirectives(pass *analysis.Pass) {
+	p := parser.NewParser()
+	p.Types = pass.Pkg
+
+	var (
+		functions []*bind.Function
+		exported  []string
+	)
+	for _, f := range pass.Files {
+		b, err := p.ParseFile(pass.Pkg.Path(), pass.Fset, f)
+		report(pass, err)
+		for _, info := range b {
+			if info.Hint == bind.HintExport {
+				exported = append(exported, info.FunctionGolang.Name)
+			}
+		}
+		functions = append(functions, b...)
+	}
+
+	for _, info := range functions {
+		report(pass, parser.Validate(info, exported...))
+	}
+}
+
+// report reports the bind.Diagnostics, using the position of the
+// Diagnostic in the files of the package.
+func report(pass *analysis.Pass, err error) {
+	diags, _ := err.(bind.Diagnostics)
+	for _, d := range diags {
+		pos := token.NoPos
+		pass.Fset.Iterate(func(f *token.File) bool {
+			if f.Name() != d.File || d.Line < 1 || d.Line > f.LineCount() {
+				return true
+			}
+			pos = f.LineStart(d.Line)
+			if d.Column > 1 && int(pos)+d.Column-1 <= f.Base()+f.Size() {
+				pos += token.Pos(d.Column - 1)
+			}
+			return false
+		})
+		pass.Report(analysis.Diagnostic{
+			Pos:      pos,
+			Category: string(d.Code),
+			Message:  d.Message,
+		})
+	}
+}
+
+// isObject reports whether t is the inkwasm.Object.
+func isObject(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == objectPath && obj.Name() == "Object"
+}
+
+// objectMethod returns the name of the method of inkwasm.Object called
+// by the given expression, or an empty string.
+func objectMethod(pass *analysis.Pass, expr ast.Expr) (*ast.SelectorExpr, string) {
+	call, ok := ast.Unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return nil, ""
+	}
+	sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return nil, ""
+	}
+	selection, ok := pass.TypesInfo.Selections[sel]
+	if !ok || selection.Kind() != types.MethodVal || !isObject(selection.Recv()) {
+		return nil, ""
+	}
+	return sel, sel.Sel.Name
+}
+
+// checkLeaks reports the Objects created in the body which are never
+// freed. Objects which are returned, passed as argument, stored or
+// captured are assumed to be freed elsewhere.
+//
+// Each assignment is one allocation, the uses of the variable belong
+// to the allocations which reach them, so a reassigned variable must be
+// freed again.
+func checkLeaks(pass *analysis.Pass, body *ast.BlockStmt) {
+	type allocation struct {
+		v       *types.Var
+		pos     token.Pos
+		end     token.Pos
+		block   ast.Node
+		method  string
+		freed   bool
+		escaped bool
+	}
+	var allocated []*allocation
+
+	var stack []ast.Node
+	track := func(lhs ast.Expr, rhs ast.Expr, end token.Pos) {
+		_, method := objectMethod(pass, rhs)
+		if !allocators[method] {
+			return
+		}
+		id, ok := lhs.(*ast.Ident)
+		if !ok {
+			return
+		}
+		if id.Name == "_" {
+			pass.Reportf(rhs.Pos(), "inkwasm.Object returned by %s is discarded, and never freed", method)
+			return
+		}
+		v, ok := pass.TypesInfo.ObjectOf(id).(*types.Var)
+		if !ok || v.Pos() < body.Pos() || v.Pos() >= body.End() {
+			// Parameters, results and package variables escape.
+			return
+		}
+		allocated = append(allocated, &allocation{v: v, pos: rhs.Pos(), end: end, block: innermostBlock(stack), method: method})
+	}
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, n)
+
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			// Checked separately.
+			stack = stack[:len(stack)-1]
+			return false
+		case *ast.AssignStmt:
+			if (n.Tok == token.DEFINE || n.Tok == token.ASSIGN) && len(n.Rhs) == 1 {
+				track(n.Lhs[0], n.Rhs[0], n.End())
+			}
+		case *ast.ValueSpec:
+			if len(n.Values) == 1 && len(n.Names) > 0 {
+				track(n.Names[0], n.Values[0], n.End())
+			}
+		case *ast.ExprStmt:
+			if _, method := objectMethod(pass, n.X); allocators[method] {
+				pass.Reportf(n.Pos(), "inkwasm.Object returned by %s is discarded, and never freed", method)
+			}
+		case *ast.SelectorExpr:
+			// Chained calls, such as o.Get("a").Get("b"), leak the
+			// intermediate Object.
+			if _, method := objectMethod(pass, n.X); allocators[method] && n.Sel.Name != "Free" {
+				if selection, ok := pass.TypesInfo.Selections[n]; ok && selection.Kind() == types.MethodVal {
+					pass.Reportf(n.X.Pos(), "inkwasm.Object returned by %s is used as receiver of %s, and never freed", method, n.Sel.Name)
+				}
+			}
+		}
+		return true
+	})
+
+	if len(allocated) == 0 {
+		return
+	}
+
+	// reaching returns the allocations of v which may be the value at
+	// pos: the last one before pos in an enclosing block, and the ones
+	// after it, in other branches. Inside closures, it's any of them.
+	reaching := func(v *types.Var, pos token.Pos, closure bool) []*allocation {
+		var r []*allocation
+		for i := len(allocated) - 1; i >= 0; i-- {
+			a := allocated[i]
+			if a.v != v {
+				continue
+			}
+			if closure {
+				r = append(r, a)
+				continue
+			}
+			if a.end > pos {
+				continue
+			}
+			r = append(r, a)
+			if a.block.Pos() <= pos && pos < a.block.End() {
+				break
+			}
+		}
+		return r
+	}
+
+	// All uses are inspected, including the ones inside of closures,
+	// a use which isn't a method call, or the target of an assignment,
+	// is an escape.
+	stack = stack[:0]
+	closures := 0
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			if _, ok := stack[len(stack)-1].(*ast.FuncLit); ok {
+				closures--
+			}
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, n)
+		if _, ok := n.(*ast.FuncLit); ok {
+			closures++
+		}
+
+		id, ok := n.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		v, ok := pass.TypesInfo.Uses[id].(*types.Var)
+		if !ok {
+			return true
+		}
+
+		parent := stack[len(stack)-2]
+		if assign, ok := parent.(*ast.AssignStmt); ok && assign.Tok == token.ASSIGN {
+			for _, lhs := range assign.Lhs {
+				if lhs == id {
+					return true
+				}
+			}
+		}
+		for _, a := range reaching(v, id.Pos(), closures > 0) {
+			if sel, ok := parent.(*ast.SelectorExpr); ok && sel.X == id {
+				if sel.Sel.Name == "Free" {
+					a.freed = true
+				}
+				continue
+			}
+			a.escaped = true
+		}
+		return true
+	})
+
+	for _, a := range allocated {
+		if !a.freed && !a.escaped {
+			pass.Reportf(a.pos, "inkwasm.Object %s, returned by %s, is never freed", a.v.Name(), a.method)
+		}
+	}
+}
+
+// innermostBlock returns the last block of the stack, such as the
+// body of an if statement or of a case clause.
+func innermostBlock(stack []ast.Node) ast.Node {
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch stack[i].(type) {
+		case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause:
+			return stack[i]
+		}
+	}
+	return nil
+}
+
+// checkUseAfterFree reports uses of Objects after Free, in the same
+// block. Deferred calls are ignored, and the Object is valid again once
+// it's assigned, after the right side of the assignment.
+func checkUseAfterFree(pass *analysis.Pass, body *ast.BlockStmt) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok {
+			// Checked separately.
+			return false
+		}
+		block, ok := n.(*ast.BlockStmt)
+		if !ok {
+			return true
+		}
+		for i, stmt := range block.List {
+			v := freedVar(pass, stmt)
+			if v == nil {
+				continue
+			}
+			for _, stmt := range block.List[i+1:] {
+				reported, assigned := false, false
+				var check func(n ast.Node) bool
+				check = func(n ast.Node) bool {
+					if reported || assigned {
+						return false
+					}
+					switch n := n.(type) {
+					case *ast.AssignStmt:
+						// The right side is evaluated first, such as
+						// x = x.Get("a").
+						for _, rhs := range n.Rhs {
+							ast.Inspect(rhs, check)
+						}
+						for _, lhs := range n.Lhs {
+							if id, ok := lhs.(*ast.Ident); ok && pass.TypesInfo.ObjectOf(id) == v {
+								assigned = true
+								continue
+							}
+							ast.Inspect(lhs, check)
+						}
+						return false
+					case *ast.Ident:
+						if pass.TypesInfo.Uses[n] == v {
+							pass.Reportf(n.Pos(), "inkwasm.Object %s is used after Free", n.Name)
+							reported = true
+						}
+					}
+					return true
+				}
+				ast.Inspect(stmt, check)
+				if reported || assigned {
+					break
+				}
+			}
+		}
+		return true
+	})
+}
+
+// freedVar returns the variable freed by the statement, such as x.Free().
+func freedVar(pass *analysis.Pass, stmt ast.Stmt) *types.Var {
+	expr, ok := stmt.(*ast.ExprStmt)
+	if !ok {
+		return nil
+	}
+	sel, method := objectMethod(pass, expr.X)
+	if method != "Free" {
+		return nil
+	}
+	id, ok := ast.Unparen(sel.X).(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	v, _ := pass.TypesInfo.Uses[id].(*types.Var)
+	return v
+}
@@ -0,0 +1,20 @@
+package inkwasmvet_test
+
+import (
+	"testing"
+
+	"github.com/inkeliz/go_inkwasm/inkwasmvet"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestDirectives(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), inkwasmvet.Analyzer, "directives")
+}
+
+func TestLeaks(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), inkwasmvet.Analyzer, "leaks")
+}
+
+func TestUseAfterFree(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), inkwasmvet.Analyzer, "usefree")
+}
@@ -0,0 +1,35 @@
+package directives
+
+import "github.com/inkeliz/go_inkwasm/inkwasm"
+
+//inkwasm:func globalThis.alert
+func alert(s string)
+
+//inkwasm:get .innerHTML
+func innerHTML(o inkwasm.Object) string
+
+//inkwasm:bogus globalThis.alert
+func bogus(s string) // want `unknown hint of 'bogus'`
+
+//inkwasm:func .getContext
+func getContext(kind string) inkwasm.Object // want `the first argument must be inkwasm.Object`
+
+//inkwasm:func globalThis.alert catch=never
+func alertCatch(s string) // want `invalid value of catch \("never"\)`
+
+// The body is reported, not the declaration.
+//
+//inkwasm:func globalThis.alert
+func alertBody(
+	s string,
+) { // want `function alertBody must not have a body`
+}
+
+//inkwasm:export
+type Point struct {
+	X int32 `js:"x"`
+	Y int32 `js:"y"`
+}
+
+//inkwasm:export
+type Mode int // want `invalid usage of export on type Mode, it must be used on structs`
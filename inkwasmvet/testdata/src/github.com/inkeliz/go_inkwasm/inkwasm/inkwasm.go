@@ -0,0 +1,27 @@
+// Package inkwasm is a stub of the runtime, with the methods used by
+// the analyzer, since the runtime only builds for js/wasm.
+package inkwasm
+
+type Object struct {
+	value uint64
+}
+
+func Global() Object { return Object{} }
+
+func (o Object) Free() {}
+
+func (o Object) Call(method string, args ...interface{}) (Object, error) { return Object{}, nil }
+
+func (o Object) Invoke(args ...interface{}) (Object, error) { return Object{}, nil }
+
+func (o Object) New(args ...interface{}) (Object, error) { return Object{}, nil }
+
+func (o Object) GetIndex(index int) Object { return Object{} }
+
+func (o Object) GetProperty(property string) Object { return Object{} }
+
+func (o Object) Get(property string) Object { return Object{} }
+
+func (o Object) SetProperty(property string, value string) {}
+
+func (o Object) MustString() string { return "" }
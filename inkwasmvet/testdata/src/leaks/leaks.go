@@ -0,0 +1,75 @@
+package leaks
+
+import "github.com/inkeliz/go_inkwasm/inkwasm"
+
+func discarded(o inkwasm.Object) {
+	_ = o.Get("a")          // want `inkwasm.Object returned by Get is discarded, and never freed`
+	o.Call("focus")         // want `inkwasm.Object returned by Call is discarded, and never freed`
+	_ = o.Get("a").Get("b") // want `inkwasm.Object returned by Get is used as receiver of Get, and never freed` `inkwasm.Object returned by Get is discarded, and never freed`
+	o.GetIndex(0).Free()
+}
+
+func chained(o inkwasm.Object) string {
+	return o.Get("a").Get("b").MustString() // want `inkwasm.Object returned by Get is used as receiver of Get, and never freed` `inkwasm.Object returned by Get is used as receiver of MustString, and never freed`
+}
+
+func leaked(o inkwasm.Object) string {
+	a := o.Get("a") // want `inkwasm.Object a, returned by Get, is never freed`
+	return a.MustString()
+}
+
+func freed(o inkwasm.Object) string {
+	a := o.Get("a")
+	defer a.Free()
+	return a.MustString()
+}
+
+func returned(o inkwasm.Object) inkwasm.Object {
+	a := o.Get("a")
+	return a
+}
+
+func argument(o inkwasm.Object, f func(inkwasm.Object)) {
+	a := o.Get("a")
+	f(a)
+}
+
+func closure(o inkwasm.Object) func() {
+	a, _ := o.Call("a")
+	return func() {
+		a.Free()
+	}
+}
+
+func reassigned(o inkwasm.Object) string {
+	x := o.Get("a")
+	x.Free()
+	x = o.Get("b") // want `inkwasm.Object x, returned by Get, is never freed`
+	return x.MustString()
+}
+
+func reassignedFreed(o inkwasm.Object) string {
+	x := o.Get("a")
+	x.Free()
+	x = o.Get("b")
+	defer x.Free()
+	return x.MustString()
+}
+
+func overwritten(o inkwasm.Object) string {
+	x := o.Get("a") // want `inkwasm.Object x, returned by Get, is never freed`
+	x = x.Get("b")
+	defer x.Free()
+	return x.MustString()
+}
+
+func branches(o inkwasm.Object, ok bool) string {
+	var x inkwasm.Object
+	if ok {
+		x = o.Get("a")
+	} else {
+		x = o.Get("b")
+	}
+	defer x.Free()
+	return x.MustString()
+}
@@ -0,0 +1,31 @@
+package usefree
+
+import "github.com/inkeliz/go_inkwasm/inkwasm"
+
+func used(o inkwasm.Object) string {
+	a := o.Get("a")
+	a.Free()
+	return a.MustString() // want `inkwasm.Object a is used after Free`
+}
+
+func deferred(o inkwasm.Object) string {
+	a := o.Get("a")
+	defer a.Free()
+	return a.MustString()
+}
+
+func reassigned(o inkwasm.Object) string {
+	a := o.Get("a")
+	a.Free()
+	a = o.Get("b")
+	defer a.Free()
+	return a.MustString()
+}
+
+func reassignedFromFreed(o inkwasm.Object) string {
+	a := o.Get("a")
+	a.Free()
+	a = a.Get("b") // want `inkwasm.Object a is used after Free`
+	defer a.Free()
+	return a.MustString()
+}
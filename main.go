@@ -4,14 +4,15 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"github.com/inkeliz/go_inkwasm/bind"
 	"github.com/inkeliz/go_inkwasm/build"
+	"github.com/inkeliz/go_inkwasm/generator"
 	"github.com/inkeliz/go_inkwasm/parser"
-	"golang.org/x/sync/errgroup"
-	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 )
@@ -20,67 +21,67 @@ var (
 	buildConfig = &build.BuilderConfig{}
 	testConfig  = &build.TesterConfig{}
 	release     bool
+	dryRun      bool
+	check       bool
+	jsonOutput  bool
 )
 
 func main() {
 	flag.Parse()
 
-	buildSet := flag.NewFlagSet("build", flag.ExitOnError)
-	buildSet.StringVar(&buildConfig.Tags, "tags", "", "Sets -Tags for 'build'")
-	buildSet.StringVar(&buildConfig.Output, "o", "", "Sets the output folder for 'build'")
-	buildSet.StringVar(&buildConfig.Compiler, "compiler", "go", "Sets the compiler (default: go)")
-	buildSet.StringVar(&buildConfig.GCFlags, "gcflags", "", "Set the compiler gcflags for 'build'")
-	buildSet.BoolVar(&release, "release", false, "Compile as release-build")
-
-	testSet := flag.NewFlagSet("test", flag.ExitOnError)
-	testSet.StringVar(&testConfig.Port, "port", "", "Sets http port")
-	testSet.StringVar(&buildConfig.Tags, "tags", "", "Sets -Tags")
-	testSet.StringVar(&buildConfig.Output, "o", "", "Sets the output folder")
-	testSet.StringVar(&testConfig.Count, "count", "1", "Run tests n times (default 1)")
-
-	benchSet := flag.NewFlagSet("bench", flag.ExitOnError)
-	benchSet.StringVar(&testConfig.Port, "port", "", "Sets http port")
-	benchSet.StringVar(&buildConfig.Tags, "tags", "", "Sets -Tags for 'build'")
-	benchSet.StringVar(&buildConfig.Output, "o", "", "Sets the output folder for 'build'")
-	benchSet.StringVar(&testConfig.BenchRun, "run", "", "Run only benchmarks matching regexp")
-	benchSet.StringVar(&testConfig.Count, "count", "1", "Run benchmarks n times (default 1)")
-	benchSet.StringVar(&testConfig.Time, "time", "", "Run each benchmark for duration d (default 5s)")
-	benchSet.StringVar(&testConfig.Shuffle, "shuffle", "off", "Run each benchmark at random order (default off)")
-
 	fn := flag.Arg(0)
-	pkg := flag.Arg(len(flag.Args()) - 1)
-	buildConfig.Source = pkg
 
-	if fn != "build" && fn != "generate" && fn != "test" && fn != "bench" {
-		fmt.Println("invalid command, should be 'generate' or 'build' or 'test' or 'bench'")
+	wd, err := os.Getwd()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	project, err := build.FindProject(wd)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// The project settings are used as default values of the flags,
+	// so flags provided in the command line take precedence.
+	settings := project.Target(fn)
+	settings.Apply(buildConfig, testConfig)
+	release = settings.Release != nil && *settings.Release
+
+	set := flagSet(fn)
+	if set == nil {
+		fmt.Println("invalid command, should be 'init' or 'generate' or 'build' or 'test' or 'bench' or 'clean'")
 		return
 	}
+	set.Parse(flag.Args()[1:])
+
+	pkg := set.Arg(0)
+	if pkg == "" && fn == "init" {
+		pkg = "."
+	}
 	if pkg == "" {
 		fmt.Println("specify a package")
 		return
 	}
+	buildConfig.Source = pkg
 
 	if release {
-		buildConfig.Ldflags = "-w -s"
+		buildConfig.Ldflags = strings.TrimSpace(buildConfig.Ldflags + " -w -s")
 	}
 
 	switch fn {
+	case "init":
+		scaffold(pkg)
 	case "generate":
 		generate(pkg)
 	case "build":
-		buildSet.Parse(flag.Args()[1:])
 		generate(pkg)
 		create()
-	case "test":
-		testSet.Parse(flag.Args()[1:])
-		generate(pkg)
-		test()
-	case "bench":
-		testConfig.BenchRun = ".*"
-		testConfig.Time = "2s"
-		benchSet.Parse(flag.Args()[1:])
+	case "test", "bench":
 		generate(pkg)
 		test()
+	case "clean":
+		clean(pkg)
 	default:
 		// impossible to hit
 		return
@@ -88,6 +89,108 @@ func main() {
 
 }
 
+// flagSet returns the flags of the given command, or nil if the command
+// is unknown. The current values of buildConfig and testConfig are used
+// as default values.
+func flagSet(fn string) *flag.FlagSet {
+	set := flag.NewFlagSet(fn, flag.ExitOnError)
+
+	compilerFlags := func() {
+		set.StringVar(&buildConfig.Tags, "tags", buildConfig.Tags, "Sets -tags")
+		set.StringVar(&buildConfig.Output, "o", buildConfig.Output, "Sets the output folder")
+		set.StringVar(&buildConfig.Compiler, "compiler", or(buildConfig.Compiler, "go"), "Sets the compiler")
+		set.StringVar(&buildConfig.GCFlags, "gcflags", buildConfig.GCFlags, "Sets the compiler gcflags")
+		set.StringVar(&buildConfig.Ldflags, "ldflags", buildConfig.Ldflags, "Sets the compiler ldflags")
+		set.StringVar(&buildConfig.Template, "template", buildConfig.Template, "Sets the index.html template")
+		set.BoolVar(&release, "release", release, "Compile as release-build")
+	}
+	testFlags := func() {
+		set.StringVar(&testConfig.Port, "port", testConfig.Port, "Sets http port")
+		set.StringVar(&testConfig.Runner, "runner", testConfig.Runner, "Sets the browser executable (default: auto-detected)")
+	}
+
+	switch fn {
+	case "init":
+	case "generate":
+		set.BoolVar(&dryRun, "n", false, "Print the files that would change, without changing them")
+		set.BoolVar(&check, "check", false, "Verify that the generated files are up-to-date, printing the diff and exiting with non-zero code otherwise")
+		set.BoolVar(&jsonOutput, "json", false, "Print the diagnostics as JSON, other messages are printed to stderr")
+	case "clean":
+		set.BoolVar(&dryRun, "n", false, "Print the files that would be removed, without removing them")
+		set.StringVar(&buildConfig.Output, "o", buildConfig.Output, "Sets the output folder")
+		set.StringVar(&buildConfig.Template, "template", buildConfig.Template, "Sets the index.html template")
+	case "build":
+		compilerFlags()
+		set.StringVar(&buildConfig.Overlay, "overlay", buildConfig.Overlay, "Sets the element id used as error overlay, or 'none' to disable it")
+		set.StringVar(&buildConfig.CrashReport, "crash-report", buildConfig.CrashReport, "Sets the endpoint that receives crash reports (default: disabled)")
+		set.BoolVar(&buildConfig.SingleFile, "single-file", buildConfig.SingleFile, "Also creates a self-contained main.html, which works from file://")
+	case "test":
+		compilerFlags()
+		testFlags()
+		set.StringVar(&testConfig.Count, "count", "1", "Run tests n times")
+	case "bench":
+		compilerFlags()
+		testFlags()
+		set.StringVar(&testConfig.BenchRun, "run", ".*", "Run only benchmarks matching regexp")
+		set.StringVar(&testConfig.Count, "count", "1", "Run benchmarks n times")
+		set.StringVar(&testConfig.Time, "time", "2s", "Run each benchmark for duration d")
+		set.StringVar(&testConfig.Shuffle, "shuffle", "off", "Run each benchmark at random order")
+	default:
+		return nil
+	}
+
+	return set
+}
+
+func or(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+func scaffold(dir string) {
+	files, err := build.Scaffold(dir)
+	for _, f := range files {
+		if f.Skipped {
+			fmt.Println("skipped", f.Path, "(already exists)")
+		} else {
+			fmt.Println("created", f.Path)
+		}
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("Next steps:")
+	if !hasGoMod(dir) {
+		fmt.Println("\tgo mod init <module>")
+	}
+	fmt.Println("\tgo get github.com/inkeliz/go_inkwasm")
+	fmt.Println("\tgo run github.com/inkeliz/go_inkwasm test .")
+	fmt.Println("\tgo run github.com/inkeliz/go_inkwasm build .")
+	fmt.Println("\tnpx serve ./wasm-build")
+}
+
+func hasGoMod(dir string) bool {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return false
+		}
+		dir = parent
+	}
+}
+
 func create() {
 	builder := build.NewBuilder(buildConfig)
 	if err := builder.Build(); err != nil {
@@ -122,68 +225,96 @@ func test() {
 }
 
 func generate(pkg string) {
-	m, err := parser.NewParser().ParsePackages(pkg)
-	if err != nil {
-		fmt.Println(err)
-		return
+	opts := generator.Options{
+		Packages: []string{pkg},
+		DryRun:   dryRun || check,
+	}
+	if check {
+		// The test files are created by "test" and "bench", and
+		// usually not committed. Files of other modules can't be
+		// changed by the user.
+		opts.Modes = []parser.Mode{parser.Release}
+		opts.MainModule = true
 	}
+	result, err := generator.Generate(context.Background(), opts)
 
-	var wg errgroup.Group
+	// Using -json, the stdout only contains the diagnostics.
+	out := os.Stdout
+	if jsonOutput {
+		out = os.Stderr
+		diags := result.Diagnostics
+		if diags == nil {
+			diags = bind.Diagnostics{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		if err := enc.Encode(diags); err != nil {
+			fmt.Fprintln(out, err)
+		}
+	}
 
-	for pkg, b := range m {
-		pkg, b := pkg, b
-		wg.Go(func() error {
-			binderRelease := parser.NewBinder(parser.Release)
-			if err := binderRelease.Create(pkg, b); err != nil {
-				return err
+	if dryRun {
+		for _, f := range result.Files {
+			if f.Action != generator.ActionNone {
+				fmt.Fprintln(out, f.Action, f.Path)
 			}
-
-			binderTests := parser.NewBinder(parser.Test)
-			if err := binderTests.Create(pkg, b); err != nil {
-				return err
+		}
+	}
+	if _, ok := err.(bind.Diagnostics); err != nil && (!ok || !jsonOutput) {
+		fmt.Fprintln(out, err)
+	}
+	if check {
+		outdated := 0
+		for _, f := range result.Files {
+			if f.Action == generator.ActionNone {
+				continue
 			}
-
-			for _, v := range []struct {
-				Source    func() io.Reader
-				File, Dir string
-			}{
-				{Source: binderRelease.JS, Dir: pkg.Dir, File: "inkwasm_js.js"},
-				{Source: binderRelease.ASM, Dir: pkg.Dir, File: "inkwasm_js.s"},
-				{Source: binderRelease.GO, Dir: pkg.Dir, File: "inkwasm_js.go"},
-				{Source: binderTests.JS, Dir: pkg.Dir, File: "inkwasm_js_test.js"},
-				{Source: binderTests.ASM, Dir: pkg.Dir, File: "inkwasm_js_test.s"},
-				{Source: binderTests.GO, Dir: pkg.Dir, File: "inkwasm_js_test.go"},
-			} {
-				f, err := os.Create(filepath.Join(v.Dir, v.File))
-				if err != nil {
-					return err
-				}
-
-				n, err := io.Copy(f, v.Source())
-				if err != nil {
-					return err
-				}
-
-				if err := f.Close(); err != nil {
-					return err
-				}
-
-				if n == 0 {
-					if err := os.Remove(filepath.Join(v.Dir, v.File)); err != nil {
-						return err
-					}
-				} else {
-					if strings.Contains(v.File, "go") {
-						exec.Command("goimports", "-w", filepath.Join(v.Dir, v.File)).Run()
-					}
-				}
+			outdated++
+			if f.Orphan {
+				fmt.Fprintln(out, "orphaned generated file, the package has no directive:", f.Path)
 			}
+			diff, err := f.Diff()
+			if err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			out.Write(diff)
+		}
+		if outdated > 0 || err != nil {
+			os.Exit(1)
+		}
+	}
+
+	// Errors fail the command, even without -check, so CI can rely on
+	// the exit code of generate, build and test.
+	if _, ok := err.(bind.Diagnostics); err != nil && !ok {
+		os.Exit(1)
+	}
+	for _, d := range result.Diagnostics {
+		if d.Severity == bind.SeverityError {
+			os.Exit(1)
+		}
+	}
+}
 
-			return nil
-		})
+func clean(pkg string) {
+	result, err := generator.Clean(context.Background(), generator.Options{
+		Packages: []string{pkg},
+		DryRun:   dryRun,
+	})
+	for _, f := range result.Files {
+		fmt.Println(f.Action, f.Path)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
 	}
 
-	if err := wg.Wait(); err != nil {
+	removed, err := build.NewBuilder(buildConfig).Clean(dryRun)
+	for _, path := range removed {
+		fmt.Println(generator.ActionRemove, path)
+	}
+	if err != nil {
 		fmt.Println(err)
 	}
 }
@@ -10,6 +10,9 @@ import (
 	"github.com/inkeliz/go_inkwasm/bind"
 )
 
+// Header is the first line of all generated files.
+const Header = `// Code generated by INKWASM BUILD; DO NOT EDIT`
+
 type Mode uint64
 
 const (
@@ -24,6 +27,9 @@ type Binder struct {
 	golang    writer
 	asmLinker writer
 	imports   writer
+
+	// results are the exported structs which can't be a result.
+	results map[string]error
 }
 
 func NewBinder(m Mode) *Binder {
@@ -35,6 +41,7 @@ func NewBinder(m Mode) *Binder {
 		golang:    writer{Buffer: bytes.NewBuffer(nil)},
 		asmLinker: writer{Buffer: bytes.NewBuffer(nil)},
 		imports:   writer{Buffer: bytes.NewBuffer(nil)},
+		results:   make(map[string]error),
 	}
 }
 
@@ -59,6 +66,20 @@ func (b *Binder) Create(pkg bind.Package, files []*bind.Function) error {
 		return err
 	}
 
+	var diags bind.Diagnostics
+	for _, f := range importFunctions {
+		if len(f.Result) > 0 && f.Result[0].ArgType == bind.ModeStatic {
+			if err, ok := b.results[strings.ToLower(f.Result[0].Type)]; ok {
+				diags.Append(f.CreateError(bind.CodeUnsupportedType, "result: struct %s can't be a result, %s", f.Result[0].Type, err.Error()))
+				continue
+			}
+		}
+		diags.Append(Validate(f))
+	}
+	if err := diags.Err(); err != nil {
+		return err
+	}
+
 	if err := b.createImports(pkg, importFunctions); err != nil {
 		return err
 	}
@@ -74,6 +95,61 @@ func (b *Binder) createExport(pkg bind.Package, files []*bind.Function) error {
 	return nil
 }
 
+// createExportSetter returns the JS function which writes the JS object
+// into the exported struct, it fails if any field can't be written.
+func createExportSetter(parent writer, info *bind.Function, size int) (string, error) {
+	w := writer{Buffer: bytes.NewBuffer(nil), t: parent.t, i: parent.i}
+	discard := writer{Buffer: bytes.NewBuffer(nil)}
+
+	// The struct is zeroed, fields missing on JS are zero.
+	w.WriteOpen(`globalThis.inkwasm.Set.%s = function(go, sp, offset, v) {`, info.FunctionGolang.Name)
+	w.Line()
+	w.Write(`sp += offset`)
+	w.Line()
+	w.Write(`globalThis.inkwasm.Set.Zero(go, sp, 0, %d)`, size)
+	w.Line()
+	w.WriteOpen(`if (v === null || v === undefined) {`)
+	w.Line()
+	w.Write(`return`)
+	w.Line()
+	w.WriteClose(`}`)
+	w.Line()
+
+	var sp = 0
+	for _, r := range info.Arguments {
+		switch {
+		case r.ArgType == bind.ModeStatic && (r.Type == "string" || r.Type == "big.Int"):
+			return "", fmt.Errorf("field %s: %s isn't supported by results", r.Name, r.Type)
+		case r.ArgType != bind.ModeStatic && r.ArgType != bind.ModeArray:
+			return "", fmt.Errorf("field %s: only numbers, bool, arrays, inkwasm.Object and exported structs are supported by results", r.Name)
+		}
+
+		if info.FunctionGolang.Size > 0 {
+			sp = int(r.Offset)
+		}
+		name := r.Name
+		if r.Tag != "" {
+			name = r.Tag
+		}
+		out, value := &w, fmt.Sprintf(`v["%s"]`, name)
+		if r.Name == "_" || r.Omit {
+			out = &discard
+		}
+		if r.Inline {
+			// The fields of the inlined struct are fields of v.
+			value = "v"
+		}
+		if err := writeJSToGo(out, r, &sp, value); err != nil {
+			return "", fmt.Errorf("field %s: %s", r.Name, err.Error())
+		}
+		out.Line()
+	}
+
+	w.WriteClose(`}`)
+	w.Line()
+	return w.String(), nil
+}
+
 func (b *Binder) createImports(pkg bind.Package, files []*bind.Function) error {
 	if err := b.createGolang(pkg, files); err != nil {
 		return err
@@ -106,7 +182,7 @@ func (b *Binder) headerAssembly() {
 	if b.asm.Len() > 0 {
 		return
 	}
-	b.asm.Write(`// Code generated by INKWASM BUILD; DO NOT EDIT`)
+	b.asm.Write(Header)
 	b.asm.Line()
 	b.asm.Write(`#include "textflag.h"`)
 	b.asm.Line()
@@ -116,10 +192,21 @@ func (b *Binder) createAssembly(_ bind.Package, info []*bind.Function) error {
 	b.headerAssembly()
 
 	for _, info := range info {
+		if info.Interface != "" {
+			// Generated methods have a body.
+			continue
+		}
+
+		// Methods are declared as Type·Method.
+		text := info.FunctionGolang.Name
+		if info.FunctionGolang.Receiver != nil {
+			text = info.FunctionGolang.Receiver.Wrapper + "·" + text
+		}
+
 		b.asm.Line()
-		b.asm.WriteOpen(`TEXT ·%s(SB), NOSPLIT, $0`, info.FunctionGolang.Name)
+		b.asm.WriteOpen(`TEXT ·%s(SB), NOSPLIT, $0`, text)
 		b.asm.Line()
-		b.asm.Write(`JMP ·_%s(SB)`, info.FunctionGolang.Name)
+		b.asm.Write(`JMP ·_%s(SB)`, info.FunctionGolang.Symbol())
 		b.asm.Line()
 		b.asm.Write(`RET`)
 		b.asm.WriteClose("")
@@ -133,7 +220,7 @@ func (b *Binder) headerGolang(pkg bind.Package, info []*bind.Function) {
 	if b.golang.Len() > 0 {
 		return
 	}
-	b.golang.Write(`// Code generated by INKWASM BUILD; DO NOT EDIT`)
+	b.golang.Write(Header)
 	b.golang.Line()
 	b.golang.Line()
 	b.golang.Write("package %s", pkg.Name)
@@ -141,6 +228,10 @@ func (b *Binder) headerGolang(pkg bind.Package, info []*bind.Function) {
 	b.golang.Line()
 	b.golang.WriteOpen("import (")
 	b.golang.Line()
+	if hasChanResult(info) {
+		b.golang.Write(`"context"`)
+		b.golang.Line()
+	}
 	b.golang.Write(`"runtime"`)
 	b.golang.Line()
 	if pkg.Path != "github.com/inkeliz/go_inkwasm/inkwasm" {
@@ -151,27 +242,80 @@ func (b *Binder) headerGolang(pkg bind.Package, info []*bind.Function) {
 	b.golang.Line()
 }
 
+// hasChanResult reports whether any function returns a channel, which
+// uses context.Background, see inkwasm.ReceiveChan.
+func hasChanResult(info []*bind.Function) bool {
+	for _, f := range info {
+		for _, r := range f.Result {
+			if r.ArgType == bind.ModeChan {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (b *Binder) createGolang(pkg bind.Package, info []*bind.Function) error {
 	b.headerGolang(pkg, info)
 
+	object, runtime := "inkwasm.Object", "inkwasm."
+	if pkg.Path == "github.com/inkeliz/go_inkwasm/inkwasm" {
+		object, runtime = "Object", ""
+	}
+
+	declared := make(map[string]bool)
 	for _, info := range info {
 		b.golang.Line()
 
+		// The type of HintBind is declared before the first method.
+		if info.Interface != "" && !declared[info.Type] {
+			declared[info.Type] = true
+			b.golang.Write("// %s implements %s, using JS.", info.Type, info.Interface)
+			b.golang.Line()
+			if info.Receiver != nil {
+				b.golang.WriteOpen("type %s struct {", info.Type)
+				b.golang.Line()
+				b.golang.Write(object)
+				b.golang.Line()
+				b.golang.WriteClose("}")
+			} else {
+				b.golang.Write("type %s struct{}", info.Type)
+			}
+			b.golang.Line()
+			b.golang.Line()
+			b.golang.Write("var _ %s = %s{}", info.Interface, info.Type)
+			b.golang.Line()
+			b.golang.Line()
+		}
+
+		// The receiver is the first argument of the generated functions.
+		args := info.Arguments
+		if info.Receiver != nil {
+			args = append([]bind.Argument{*info.Receiver}, args...)
+		}
+
 		params := []struct {
 			ArgsString     *strings.Builder
 			StubArgsString *strings.Builder
 			ValString      *strings.Builder
 			Args           []bind.Argument
 		}{
-			{ArgsString: new(strings.Builder), ValString: new(strings.Builder), StubArgsString: new(strings.Builder), Args: info.Arguments},
+			{ArgsString: new(strings.Builder), ValString: new(strings.Builder), StubArgsString: new(strings.Builder), Args: args},
 			{ArgsString: new(strings.Builder), ValString: new(strings.Builder), StubArgsString: new(strings.Builder), Args: info.Result},
 		}
 
 		var (
 			keepAlive    []string
+			prelude      []string
+			free         []string
 			decoder      string
 			unsafeConv   string
 			unsafeResize int
+			wrapper      = "%s"
+			bigInt       bool
+			importArgs   []string
+			methodArgs   []string
+			methodVals   []string
 		)
 		for d, p := range params {
 			p := p
@@ -179,6 +323,11 @@ func (b *Binder) createGolang(pkg bind.Package, info []*bind.Function) error {
 				if arg.Type == "inkwasm.Object" && pkg.Path == "github.com/inkeliz/go_inkwasm/inkwasm" {
 					arg.Type = "Object"
 				}
+				if arg.SubType != nil && arg.SubType.Type == "inkwasm.Object" && pkg.Path == "github.com/inkeliz/go_inkwasm/inkwasm" {
+					sub := *arg.SubType
+					sub.Type = "Object"
+					arg.SubType = &sub
+				}
 				if arg.Name == "" || arg.Name == "_" {
 					if d == 0 {
 						arg.Name = "p" + strconv.Itoa(i)
@@ -186,10 +335,32 @@ func (b *Binder) createGolang(pkg bind.Package, info []*bind.Function) error {
 						arg.Name = "_"
 					}
 				}
+				val, start := arg.Name, p.ArgsString.Len()
 				switch arg.ArgType {
 				case bind.ModeStatic:
+					if arg.Wrapper != "" {
+						// Named inkwasm.Object, the JS function uses the Object.
+						p.ArgsString.WriteString(fmt.Sprintf("%s %s", arg.Name, arg.Wrapper))
+						p.StubArgsString.WriteString(fmt.Sprintf("%s %s", arg.Name, arg.Type))
+						switch {
+						case d == 0 && arg.Embedded:
+							val = arg.Name + ".Object"
+						case d == 0:
+							val = fmt.Sprintf("%s(%s)", arg.Type, arg.Name)
+						case arg.Embedded:
+							wrapper = arg.Wrapper + "{Object: %s}"
+						default:
+							wrapper = arg.Wrapper + "(%s)"
+						}
+						break
+					}
 					p.ArgsString.WriteString(fmt.Sprintf("%s %s", arg.Name, arg.Type))
-					if arg.Type == "string" {
+					if arg.Type == "big.Int" && d == 1 {
+						// JS function must use Object
+						p.StubArgsString.WriteString(fmt.Sprintf("%s %s", arg.Name, object))
+						decoder, wrapper = ".MustBigInt()", "*%s"
+						bigInt = true
+					} else if arg.Type == "string" {
 						// JS function must use Object
 						obj := "inkwasm.Object"
 						if pkg.Path == "github.com/inkeliz/go_inkwasm/inkwasm" {
@@ -206,8 +377,24 @@ func (b *Binder) createGolang(pkg bind.Package, info []*bind.Function) error {
 						p.StubArgsString.WriteString(fmt.Sprintf("%s %s", arg.Name, arg.Type))
 					}
 				case bind.ModePointer:
-					p.ArgsString.WriteString(fmt.Sprintf("%s *%s", arg.Name, arg.SubType.Type))
-					p.StubArgsString.WriteString(fmt.Sprintf("%s *%s", arg.Name, arg.Type))
+					elem := arg.SubType.Type
+					if arg.SubType.ArgType == bind.ModeArray {
+						elem = fmt.Sprintf("[%d]%s", arg.SubType.Len, arg.SubType.SubType.Type)
+					}
+					p.ArgsString.WriteString(fmt.Sprintf("%s *%s", arg.Name, elem))
+					if arg.SubType.Type == "big.Int" && d == 1 {
+						// JS function must use Object
+						p.StubArgsString.WriteString(fmt.Sprintf("%s %s", arg.Name, object))
+						decoder = ".MustBigInt()"
+						break
+					}
+					if d == 1 {
+						// JS function must use Object, nil if null or undefined.
+						p.StubArgsString.WriteString(fmt.Sprintf("%s %s", arg.Name, object))
+						wrapper = fmt.Sprintf("%sDecodePtr[%s](%%s)", runtime, elem)
+						break
+					}
+					p.StubArgsString.WriteString(fmt.Sprintf("%s *%s", arg.Name, elem))
 					if d == 0 {
 						keepAlive = append(keepAlive, arg.Name)
 					}
@@ -218,7 +405,11 @@ func (b *Binder) createGolang(pkg bind.Package, info []*bind.Function) error {
 						keepAlive = append(keepAlive, arg.Name)
 					}
 				case bind.ModeSlice:
-					p.ArgsString.WriteString(fmt.Sprintf("%s []%s", arg.Name, arg.SubType.Type))
+					if arg.Variadic {
+						p.ArgsString.WriteString(fmt.Sprintf("%s ...%s", arg.Name, arg.SubType.Type))
+					} else {
+						p.ArgsString.WriteString(fmt.Sprintf("%s []%s", arg.Name, arg.SubType.Type))
+					}
 					// JS function must use Object
 					obj := "inkwasm.Object"
 					if pkg.Path == "github.com/inkeliz/go_inkwasm/inkwasm" {
@@ -236,9 +427,60 @@ func (b *Binder) createGolang(pkg bind.Package, info []*bind.Function) error {
 					if d == 0 {
 						keepAlive = append(keepAlive, arg.Name)
 					}
+				case bind.ModeChan:
+					p.ArgsString.WriteString(fmt.Sprintf("%s %s %s", arg.Name, arg.Dir, arg.SubType.Type))
+					// JS function must use Object
+					p.StubArgsString.WriteString(fmt.Sprintf("%s %s", arg.Name, object))
+					if d == 0 {
+						val = "c" + strconv.Itoa(i)
+						prelude = append(prelude, fmt.Sprintf("%s := %sSendChan(%s)", val, runtime, arg.Name))
+						free = append(free, val)
+					}
+					if d == 1 {
+						wrapper = fmt.Sprintf("%sReceiveChan[%s](context.Background(), %%s)", runtime, arg.SubType.Type)
+					}
+				case bind.ModeMap:
+					p.ArgsString.WriteString(fmt.Sprintf("%s map[%s]%s", arg.Name, arg.Key.Type, arg.SubType.Type))
+					// JS function must use Object
+					p.StubArgsString.WriteString(fmt.Sprintf("%s %s", arg.Name, object))
+					if d == 0 {
+						// The keys and values are given as slices.
+						keys, values := "k"+strconv.Itoa(i), "v"+strconv.Itoa(i)
+						val = keys + ", " + values
+						prelude = append(prelude, fmt.Sprintf("%s, %s := %sMapEntries(%s)", keys, values, runtime, arg.Name))
+						keepAlive = append(keepAlive, keys, values)
+					}
+					if d == 1 {
+						wrapper = fmt.Sprintf("%sDecodeMap[%s, %s](%%s)", runtime, arg.Key.Type, arg.SubType.Type)
+					}
 				}
 
-				p.ValString.WriteString(arg.Name)
+				// The imported function receives the inkwasm.Object,
+				// instead of the named type.
+				if d == 0 {
+					switch {
+					case arg.Wrapper != "":
+						importArgs = append(importArgs, fmt.Sprintf("%s %s", arg.Name, arg.Type))
+					case arg.ArgType == bind.ModeChan:
+						importArgs = append(importArgs, fmt.Sprintf("%s %s", arg.Name, object))
+					case arg.ArgType == bind.ModeMap:
+						importArgs = append(importArgs, fmt.Sprintf("%s_keys []%s, %s_values []%s", arg.Name, arg.Key.Type, arg.Name, arg.SubType.Type))
+					case arg.Variadic:
+						importArgs = append(importArgs, fmt.Sprintf("%s []%s", arg.Name, arg.SubType.Type))
+					default:
+						importArgs = append(importArgs, p.ArgsString.String()[start:])
+					}
+					if info.Receiver == nil || i > 0 {
+						methodArgs = append(methodArgs, p.ArgsString.String()[start:])
+					}
+					if arg.Variadic {
+						methodVals = append(methodVals, arg.Name+"...")
+					} else {
+						methodVals = append(methodVals, arg.Name)
+					}
+				}
+
+				p.ValString.WriteString(val)
 				if i != len(p.Args)-1 {
 					p.StubArgsString.WriteString(", ")
 					p.ArgsString.WriteString(", ")
@@ -247,20 +489,28 @@ func (b *Binder) createGolang(pkg bind.Package, info []*bind.Function) error {
 			}
 		}
 		b.golang.Line()
-		b.golang.WriteOpen("func _%s(%s) (%s) {", info.FunctionGolang.Name, params[0].ArgsString.String(), params[1].ArgsString.String())
+		b.golang.WriteOpen("func _%s(%s) (%s) {", info.FunctionGolang.Symbol(), params[0].ArgsString.String(), params[1].ArgsString.String())
 		b.golang.Line()
+		for _, a := range prelude {
+			b.golang.Write(`%s`, a)
+			b.golang.Line()
+		}
 		if len(info.FunctionGolang.Result) == 1 {
 			b.golang.Write(`r0 :=`)
 		}
 		if len(info.FunctionGolang.Result) == 2 {
 			b.golang.Write(`r0, r1 :=`)
 		}
-		b.golang.Write(`__%s(%s)`, info.FunctionGolang.Name, params[0].ValString.String())
+		b.golang.Write(`__%s(%s)`, info.FunctionGolang.Symbol(), params[0].ValString.String())
 		b.golang.Line()
 		for _, a := range keepAlive {
 			b.golang.Write(`runtime.KeepAlive(%s)`, a)
 			b.golang.Line()
 		}
+		for _, a := range free {
+			b.golang.Write(`%s.Free()`, a)
+			b.golang.Line()
+		}
 		var resultVar = "r0"
 		if decoder != "" {
 			resultVar = "rx"
@@ -268,14 +518,25 @@ func (b *Binder) createGolang(pkg bind.Package, info []*bind.Function) error {
 			b.golang.Line()
 			b.golang.Write(`r0.Free()`)
 		}
+		if bigInt {
+			// The big.Int is nil if it's not a BigInt, such as null.
+			b.golang.Line()
+			b.golang.WriteOpen(`if rx == nil {`)
+			b.golang.Line()
+			b.golang.Write(`rx = new(big.Int)`)
+			b.golang.Line()
+			b.golang.WriteClose(`}`)
+		}
 		if unsafeResize > 1 {
 			b.golang.Line()
-			b.golang.Write(`(*[3]int)(unsafe.Pointer(&rx))[1] = %d`, unsafeResize)
-			b.golang.Write(`(*[3]int)(unsafe.Pointer(&rx))[2] = %d`, unsafeResize)
+			b.golang.Write(`(*[3]int)(unsafe.Pointer(&rx))[1] /= %d`, unsafeResize)
+			b.golang.Line()
+			b.golang.Write(`(*[3]int)(unsafe.Pointer(&rx))[2] /= %d`, unsafeResize)
 		}
 		if unsafeConv != "" {
 			resultVar = fmt.Sprintf(`*(*%s)(unsafe.Pointer(&rx))`, unsafeConv)
 		}
+		resultVar = fmt.Sprintf(wrapper, resultVar)
 		b.golang.Line()
 		if len(info.FunctionGolang.Result) == 1 {
 			b.golang.Write(`return %s`, resultVar)
@@ -292,28 +553,47 @@ func (b *Binder) createGolang(pkg bind.Package, info []*bind.Function) error {
 			path = "main"
 		}
 
-		b.golang.Write("//go:wasmimport gojs %s.__%s", path, info.FunctionGolang.Name)
+		b.golang.Write("//go:wasmimport gojs %s.__%s", path, info.FunctionGolang.Symbol())
 		b.golang.Line()
-		b.golang.Write("func __%s(%s) (%s)", info.FunctionGolang.Name, params[0].ArgsString.String(), params[1].StubArgsString.String())
+		b.golang.Write("func __%s(%s) (%s)", info.FunctionGolang.Symbol(), strings.Join(importArgs, ", "), params[1].StubArgsString.String())
 		b.golang.Line()
 		b.golang.Line()
+
+		if info.Interface != "" {
+			recv := "_"
+			if info.Receiver != nil {
+				recv = info.Receiver.Name
+			}
+			b.golang.WriteOpen("func (%s %s) %s(%s) (%s) {", recv, info.Type, info.FunctionGolang.Name, strings.Join(methodArgs, ", "), params[1].ArgsString.String())
+			b.golang.Line()
+			call := fmt.Sprintf("_%s(%s)", info.FunctionGolang.Symbol(), strings.Join(methodVals, ", "))
+			if len(info.FunctionGolang.Result) > 0 {
+				call = "return " + call
+			}
+			b.golang.Write("%s", call)
+			b.golang.Line()
+			b.golang.WriteClose("}")
+			b.golang.Line()
+			b.golang.Line()
+		}
 	}
 
 	return nil
 }
 
 func (b *Binder) createExportJavascript(pkg bind.Package, info []*bind.Function) error {
-	b.js.Write(`// Code generated by INKWASM BUILD; DO NOT EDIT`)
+	b.js.Write(Header)
 	b.js.Line()
 	b.js.WriteOpen(`(() => {`)
 	b.js.Line()
 	b.js.WriteOpen(`if (globalThis.inkwasm === undefined) {`)
 	b.js.Line()
-	b.js.Write(`globalThis.inkwasm = {Load: {}};`)
+	b.js.Write(`globalThis.inkwasm = {Load: {}, Set: {}};`)
 	b.js.Line()
 	b.js.WriteClose(`}`)
 	b.js.Line()
 
+	var diags bind.Diagnostics
 	for _, info := range info {
 		decoderName := fmt.Sprintf(`globalThis.inkwasm.Load.%s`, info.FunctionGolang.Name)
 
@@ -322,50 +602,132 @@ func (b *Binder) createExportJavascript(pkg bind.Package, info []*bind.Function)
 		b.js.Line()
 		b.js.Write(`sp += offset`)
 		b.js.Line()
-		b.js.WriteOpen(`return {`)
+
+		// Fields with omitempty or inline are set after the object
+		// literal, into the result.
+		tail, special := writer{Buffer: bytes.NewBuffer(nil), t: b.js.t, i: b.js.i}, false
+		for _, r := range info.Arguments {
+			special = special || r.OmitEmpty || r.Inline
+		}
+		if special {
+			b.js.WriteOpen(`let result = {`)
+		} else {
+			b.js.WriteOpen(`return {`)
+		}
 		b.js.Line()
 
 		var sp = 0
 
+		discard := writer{Buffer: bytes.NewBuffer(nil)}
 		for _, r := range info.Arguments {
+			if info.FunctionGolang.Size > 0 {
+				sp = int(r.Offset)
+			}
+			if r.Name == "_" || r.Omit {
+				// Blank and omitted fields are not decoded.
+				if err := writeGoToJS(&discard, r, &sp); err != nil {
+					diags.Append(info.CreateError(bind.CodeUnsupportedType, "field %s: %s", r.Name, err.Error()))
+				}
+				continue
+			}
 			name := r.Name
 			if r.Tag != "" {
 				name = r.Tag
 			}
-			b.js.Write(`"%s":`, name)
-			if err := writeGoToJS(&b.js, r, &sp); err != nil {
-				return info.CreateError(err.Error())
+
+			out := &b.js
+			switch {
+			case r.Inline:
+				if f, ok := bind.BridgeFunc[bind.ModeStatic][strings.ToLower(r.Type)]; r.ArgType != bind.ModeStatic || !ok || f.Align == 0 {
+					diags.Append(info.CreateError(bind.CodeUnsupportedType, "field %s: inline requires an exported struct", r.Name))
+					continue
+				}
+				out = &tail
+				out.Write(`Object.assign(result, `)
+			case r.OmitEmpty:
+				out = &tail
+				out.Write(`globalThis.inkwasm.Internal.OmitEmpty(result, "%s", `, name)
+			default:
+				out.Write(`"%s":`, name)
 			}
-			b.js.WriteInline(",")
-			b.js.Line()
+			if err := writeGoToJS(out, r, &sp); err != nil {
+				diags.Append(info.CreateError(bind.CodeUnsupportedType, "field %s: %s", r.Name, err.Error()))
+			}
+			if r.ArgType == bind.ModePointer {
+				// Nil pointers are null, instead of undefined.
+				out.WriteInline(" ?? null")
+			}
+			if out == &tail {
+				out.WriteInline(")")
+			} else {
+				out.WriteInline(",")
+			}
+			out.Line()
 		}
 
-		size := sp
+		size, align := sp, 8
 		if left := size % 8; left != 0 {
 			size += 8 - left
 		}
+		if info.FunctionGolang.Size > 0 {
+			size, align = int(info.FunctionGolang.Size), int(info.FunctionGolang.Align)
+		}
 
 		bind.BridgeFunc[bind.ModeStatic][strings.ToLower(info.FunctionGolang.Name)] = bind.BridgeFuncInfo{
-			JS:   decoderName,
-			Size: size,
+			JS:    decoderName,
+			Size:  size,
+			Align: align,
+		}
+		// Arrays and slices are arrays of objects.
+		bind.BridgeFunc[bind.ModeArray][strings.ToLower(info.FunctionGolang.Name)] = bind.BridgeFuncInfo{
+			JS:    fmt.Sprintf(`globalThis.inkwasm.Load.ArrayOf(%s, %d)`, decoderName, size),
+			Size:  size,
+			Align: align,
 		}
 
 		b.js.Line()
 		b.js.WriteClose(`}`)
 		b.js.Line()
+		if special {
+			b.js.WriteString(tail.String())
+			b.js.Write(`return result`)
+			b.js.Line()
+		}
 		b.js.WriteClose(`}`)
 		b.js.Line()
+
+		// The struct can be a result if all fields are written directly
+		// into the Go memory, strings and slices need the Go side.
+		setter, err := createExportSetter(b.js, info, size)
+		if err != nil {
+			b.results[strings.ToLower(info.FunctionGolang.Name)] = err
+			continue
+		}
+		b.js.Line()
+		b.js.WriteString(setter)
+
+		setterName := fmt.Sprintf(`globalThis.inkwasm.Set.%s`, info.FunctionGolang.Name)
+		bind.ResultFunc[bind.ModeStatic][strings.ToLower(info.FunctionGolang.Name)] = bind.BridgeFuncInfo{
+			JS:    setterName,
+			Size:  size,
+			Align: align,
+		}
+		bind.ResultFunc[bind.ModeArray][strings.ToLower(info.FunctionGolang.Name)] = bind.BridgeFuncInfo{
+			JS:    setterName,
+			Size:  size,
+			Align: align,
+		}
 	}
 
 	b.js.Line()
 	b.js.WriteClose(`})();`)
 	b.js.Line()
 
-	return nil
+	return diags.Err()
 }
 
 func (b *Binder) createJavascript(pkg bind.Package, info []*bind.Function) error {
-	b.js.Write(`// Code generated by INKWASM BUILD; DO NOT EDIT`)
+	b.js.Write(Header)
 	b.js.Line()
 	b.js.WriteOpen(`(() => {`)
 	b.js.Line()
@@ -379,20 +741,45 @@ func (b *Binder) createJavascript(pkg bind.Package, info []*bind.Function) error
 
 	for _, info := range info {
 		b.js.Line()
-		b.js.WriteOpen(`"%s.__%s": (sp) => {`, path, info.FunctionGolang.Name)
+		b.js.WriteOpen(`"%s.__%s": (sp) => {`, path, info.FunctionGolang.Symbol())
 		b.js.Line()
 
 		var (
 			sp                                 = 8
 			resultHolder                       = ""
 			functionInjection                  = ""
+			receiverInjection                  = ""
 			functionExecStart, functionExecEnd = "", ""
 		)
 
-		if strings.HasPrefix(info.FunctionJavascript.Name, ".") {
-			if len(info.FunctionGolang.Arguments) == 0 || !strings.EqualFold(info.FunctionGolang.Arguments[0].Type, "inkwasm.object") {
-				return info.CreateError("the first argument must be inkwasm.Object, since it starts with dot (%s)", info.FunctionJavascript.Name)
+		if info.FunctionJavascript.Options[bind.OptionComplex] == "interleaved" {
+			info.FunctionGolang.Arguments = interleaved(info.FunctionGolang.Arguments)
+			info.FunctionGolang.Result = interleaved(info.FunctionGolang.Result)
+		}
+		if info.FunctionJavascript.Options[bind.OptionMap] == "map" {
+			info.FunctionGolang.Arguments = jsMaps(info.FunctionGolang.Arguments)
+		}
+
+		// The function is already validated by Validate.
+		template := IsTemplate(info.FunctionJavascript.Name)
+		if info.FunctionGolang.Receiver != nil {
+			// The receiver is the JS this, it's used as $this by templates,
+			// and Function.call otherwise.
+			f := bind.BridgeFunc[bind.ModeStatic]["inkwasm.object"]
+			switch {
+			case template:
+				b.js.Write("const $this = %s(go, sp, %d)", f.JS, sp)
+				b.js.Line()
+			case strings.HasPrefix(info.FunctionJavascript.Name, "."):
+				functionInjection = fmt.Sprintf("%s(go, sp, %d)", f.JS, sp)
+			default:
+				b.js.Write("const $this = %s(go, sp, %d)", f.JS, sp)
+				b.js.Line()
+				info.FunctionJavascript.Name += ".call"
+				receiverInjection = "$this"
 			}
+			sp += f.Size
+		} else if !template && strings.HasPrefix(info.FunctionJavascript.Name, ".") {
 			f := bind.BridgeFunc[bind.ModeStatic]["inkwasm.object"]
 			functionInjection = fmt.Sprintf("%s(go, sp, %d)", f.JS, sp)
 			sp += f.Size
@@ -405,15 +792,12 @@ func (b *Binder) createJavascript(pkg bind.Package, info []*bind.Function) error
 
 		switch info.FunctionJavascript.Hint {
 		case bind.HintGet:
-			if len(info.FunctionGolang.Arguments) > 1 {
-				return info.CreateError("invalid usage of %s. Function can't have more than one argument", info.FunctionJavascript.Hint)
-			}
 			if len(info.FunctionGolang.Arguments) > 0 {
 				functionExecStart, functionExecEnd = "[", "]"
 			}
 			resultHolder = "let r = "
 		case bind.HintFunc:
-			if len(info.FunctionGolang.Result) > 0 {
+			if len(info.FunctionGolang.Result) > 0 || info.FunctionJavascript.Options.Has(bind.OptionOut) {
 				resultHolder = "let r = "
 			}
 			functionExecStart, functionExecEnd = "(", ")"
@@ -421,71 +805,213 @@ func (b *Binder) createJavascript(pkg bind.Package, info []*bind.Function) error
 			resultHolder = "let r = new "
 			functionExecStart, functionExecEnd = "(", ")"
 		case bind.HintSet:
-			if len(info.FunctionGolang.Arguments) != 1 {
-				return info.CreateError("invalid usage of %s. Function must have one argument.", info.FunctionJavascript.Hint)
-			}
-			if len(info.FunctionGolang.Result) > 0 {
-				return info.CreateError("invalid usage of %s. Set can have results.", info.FunctionJavascript.Hint)
-			}
 			functionExecStart, functionExecEnd = " = ", ""
-		default:
-			return info.CreateError("unknown hint of '%s'. The comment must be //inkwasm:{hint}, where {hint} can be either 'func', 'get', 'set', 'new'.", info.FunctionJavascript.Hint)
 		}
 
-		if len(info.Result) > 2 {
-			return info.CreateError("function can only have a maximum of 2 results, currently having %d.", len(info.Result))
+		// The exceptions are caught if there's a bool to report it, or
+		// nothing to return, using the catch option. The bool of nullable
+		// reports the absence, exceptions are only caught using catch.
+		catch := info.FunctionJavascript.Options[bind.OptionCatch]
+		if catch == "" {
+			catch = "log"
+		}
+		explicit := info.FunctionJavascript.Options.Has(bind.OptionCatch)
+		tryCatch := catch != "throw" && ((len(info.Result) == 2 && (explicit || !info.FunctionJavascript.Options.Has(bind.OptionNullable))) || (len(info.Result) == 0 && explicit))
+
+		clone := info.FunctionJavascript.Options[bind.OptionCopy] == "args"
+
+		// The out argument receives the result, instead of being given
+		// to the function, the inout arguments are kept to be written
+		// back after the call.
+		type writeBackArgument struct {
+			bind.Argument
+			sp int
+			v  string
+		}
+		var writeBacks []writeBackArgument
+		out := info.FunctionJavascript.Options[bind.OptionOut]
+		inout := make(map[string]bool)
+		for _, name := range info.FunctionJavascript.Options.List(bind.OptionInOut) {
+			inout[name] = true
+		}
+		skipOut := func(r bind.Argument) bool {
+			if out == "" || r.Name != out {
+				return false
+			}
+			padding(&sp, 8)
+			writeBacks = append(writeBacks, writeBackArgument{Argument: r, sp: sp, v: "r"})
+			sp += 8
+			return true
+		}
+		writeArgument := func(r bind.Argument) error {
+			if r.Variadic {
+				// Empty slices are null.
+				if !template {
+					b.js.WriteInline("...")
+				}
+				b.js.WriteInline("(")
+				defer b.js.WriteInline(" || [])")
+			}
+			if clone && r.ArgType != bind.ModeStatic {
+				b.js.WriteInline("globalThis.inkwasm.Internal.Clone(")
+				defer b.js.WriteInline(")")
+			}
+			return writeGoToJS(&b.js, r, &sp)
 		}
-		if len(info.Result) == 2 && info.Result[1].Type != "bool" {
-			return info.CreateError("when two results is provided, the last result of the function must be 'bool'")
+
+		// Templates use the arguments as placeholders, such as $0, each
+		// argument is decoded once, even if used multiple times.
+		if template {
+			for i, r := range info.Arguments {
+				if skipOut(r) {
+					continue
+				}
+				if inout[r.Name] {
+					padding(&sp, 8)
+					writeBacks = append(writeBacks, writeBackArgument{Argument: r, sp: sp, v: fmt.Sprintf("$%d", i)})
+				}
+				b.js.Write("const $%d = ", i)
+				if err := writeArgument(r); err != nil {
+					return info.CreateError(bind.CodeUnsupportedType, err.Error())
+				}
+				b.js.Line()
+			}
 		}
 
-		if len(info.Result) == 2 {
+		if tryCatch {
 			b.js.WriteOpen("try {")
 			b.js.Line()
 		}
 
-		b.js.Write("%s%s%s%s", resultHolder, functionInjection, info.FunctionJavascript.Name, functionExecStart)
+		if template {
+			if info.FunctionJavascript.Hint == bind.HintSet {
+				b.js.Write("%s = $%d", info.FunctionJavascript.Name, len(info.Arguments)-1)
+			} else {
+				b.js.Write("%s%s", resultHolder, info.FunctionJavascript.Name)
+			}
+			b.js.Line()
+		} else {
+			for i, r := range info.Arguments {
+				if inout[r.Name] {
+					b.js.Write("let $%d", i)
+					b.js.Line()
+				}
+			}
+
+			b.js.Write("%s%s%s%s", resultHolder, functionInjection, info.FunctionJavascript.Name, functionExecStart)
 
-		last := len(info.Arguments) - 1
-		for i, r := range info.Arguments {
-			if err := writeGoToJS(&b.js, r, &sp); err != nil {
-				return info.CreateError(err.Error())
+			if receiverInjection != "" {
+				b.js.WriteInline(receiverInjection)
+				if len(info.Arguments) > 0 {
+					b.js.WriteInline(",")
+				}
 			}
-			if last != i {
-				b.js.WriteInline(",")
+
+			// Nil pointers are undefined, the trailing ones are omitted,
+			// instead of given as undefined.
+			last := len(info.Arguments) - 1
+			if last >= 0 && out != "" && info.Arguments[last].Name == out {
+				last--
+			}
+			optional := last >= 0 && info.Arguments[last].ArgType == bind.ModePointer && functionExecStart == "("
+			if optional {
+				b.js.WriteInline("...globalThis.inkwasm.Internal.Trim([")
+			}
+			for i, r := range info.Arguments {
+				if skipOut(r) {
+					continue
+				}
+				if inout[r.Name] {
+					padding(&sp, 8)
+					writeBacks = append(writeBacks, writeBackArgument{Argument: r, sp: sp, v: fmt.Sprintf("$%d", i)})
+					b.js.WriteInline("$%d = ", i)
+				}
+				if err := writeArgument(r); err != nil {
+					return info.CreateError(bind.CodeUnsupportedType, err.Error())
+				}
+				if i < last {
+					b.js.WriteInline(",")
+				}
+			}
+			if optional {
+				b.js.WriteInline("])")
 			}
+			b.js.WriteInline(functionExecEnd)
+			b.js.Line()
 		}
-		b.js.WriteInline(functionExecEnd)
-		b.js.Line()
 
 		padding(&sp, sp)
 
-		if len(info.Result) > 0 {
+		var resultSp int
+		if len(info.Result) > 0 || len(writeBacks) > 0 {
 			b.js.Write(`sp = go._inst.exports.getsp() >>> 0`)
 			b.js.Line()
+		}
+		if len(info.Result) > 0 {
+
+			if info.FunctionJavascript.Options.Has(bind.OptionNullable) {
+				// The offset of the bool is known after the first
+				// result is written, into a discarded buffer.
+				discard, boolSp := writer{Buffer: bytes.NewBuffer(nil)}, sp
+				if err := writeJSToGo(&discard, info.Result[0], &boolSp, "r"); err != nil {
+					return info.CreateError(bind.CodeUnsupportedType, err.Error())
+				}
+
+				b.js.WriteOpen("if (r === null || r === undefined) {")
+				b.js.Line()
+				if err := writeJSToGo(&b.js, info.Result[1], &boolSp, "false"); err != nil {
+					return info.CreateError(bind.CodeUnsupportedType, err.Error())
+				}
+				b.js.Line()
+				b.js.WriteClose("")
+				b.js.WriteOpen("} else {")
+				b.js.Line()
+			}
+
 			if err := writeJSToGo(&b.js, info.Result[0], &sp, "r"); err != nil {
-				return info.CreateError(err.Error())
+				return info.CreateError(bind.CodeUnsupportedType, err.Error())
 			}
 			if len(info.Result) > 1 {
 				b.js.Line()
+				resultSp = sp
 				if err := writeJSToGo(&b.js, info.Result[1], &sp, "true"); err != nil {
-					return info.CreateError(err.Error())
+					return info.CreateError(bind.CodeUnsupportedType, err.Error())
 				}
 			}
+
+			if info.FunctionJavascript.Options.Has(bind.OptionNullable) {
+				b.js.Line()
+				b.js.WriteClose("}")
+			}
+			if len(writeBacks) > 0 {
+				b.js.Line()
+			}
 		}
 
-		if len(info.Result) == 2 {
+		for i, r := range writeBacks {
+			if i > 0 {
+				b.js.Line()
+			}
+			if err := writeBack(&b.js, r.Argument, r.sp, r.v); err != nil {
+				return info.CreateError(bind.CodeUnsupportedType, "argument %s: %s", r.Name, err.Error())
+			}
+		}
+
+		if tryCatch {
 			b.js.Line()
 			b.js.WriteClose("")
 			b.js.WriteOpen("}catch(e){")
 			b.js.Line()
-			b.js.Write("console.log(e)")
-			b.js.Line()
-			sp -= 1
-			if err := writeJSToGo(&b.js, info.Result[1], &sp, "false"); err != nil {
-				return info.CreateError(err.Error())
+			if catch == "log" {
+				b.js.Write("console.log(e)")
+				b.js.Line()
+			}
+			if len(info.Result) == 2 {
+				if err := writeJSToGo(&b.js, info.Result[1], &resultSp, "false"); err != nil {
+					return info.CreateError(bind.CodeUnsupportedType, err.Error())
+				}
+				b.js.Line()
 			}
-			b.js.Line()
 			b.js.WriteClose("}")
 		}
 
@@ -502,6 +1028,44 @@ func (b *Binder) createJavascript(pkg bind.Package, info []*bind.Function) error
 	return nil
 }
 
+// interleaved returns the arguments using the interleaved real and
+// imaginary parts, for arrays and slices of complex numbers, such as
+// [2]float32 for [1]complex64.
+func interleaved(args []bind.Argument) []bind.Argument {
+	floats := map[string]string{"complex64": "float32", "complex128": "float64"}
+
+	result := make([]bind.Argument, len(args))
+	for i, arg := range args {
+		result[i] = arg
+		if arg.SubType == nil || floats[arg.SubType.Type] == "" {
+			continue
+		}
+		sub := *arg.SubType
+		switch arg.ArgType {
+		case bind.ModeArray:
+			result[i].Len *= 2
+			sub.Type = floats[sub.Type]
+		case bind.ModeSlice:
+			sub.Type += "/interleaved"
+		}
+		result[i].SubType = &sub
+	}
+	return result
+}
+
+// jsMaps returns the arguments using Map, instead of plain objects,
+// for maps.
+func jsMaps(args []bind.Argument) []bind.Argument {
+	result := make([]bind.Argument, len(args))
+	for i, arg := range args {
+		result[i] = arg
+		if arg.ArgType == bind.ModeMap {
+			result[i].Type = "map"
+		}
+	}
+	return result
+}
+
 func padding(sp *int, l int) {
 	if l > 8 {
 		l = 8
@@ -518,7 +1082,7 @@ func writeGoToJS(w *writer, r bind.Argument, sp *int) error {
 		if !ok {
 			return fmt.Errorf("invalid type of %s", r.Type)
 		}
-		padding(sp, f.Size)
+		padding(sp, f.Alignment())
 		w.WriteInline(`%s(go, sp, %d)`, f.JS, *sp)
 		*sp += f.Size
 	case bind.ModeArray:
@@ -527,7 +1091,7 @@ func writeGoToJS(w *writer, r bind.Argument, sp *int) error {
 		if !ok {
 			return fmt.Errorf("invalid type of array %s", r.Type)
 		}
-		padding(sp, f.Size)
+		padding(sp, f.Alignment())
 		w.WriteInline(`%s(go, sp, %d, %d, %s)`, array.JS, *sp, r.Len, f.JS)
 		*sp += int(r.Len) * f.Size
 	case bind.ModeSlice:
@@ -542,17 +1106,80 @@ func writeGoToJS(w *writer, r bind.Argument, sp *int) error {
 	case bind.ModePointer:
 		ptr, _ := bind.BridgeFunc[r.ArgType]["default"]
 		padding(sp, ptr.Size)
+		if r.SubType.ArgType == bind.ModeArray {
+			// The array is decoded at the pointer, with offset 0.
+			array, offset := writer{Buffer: bytes.NewBuffer(nil)}, 0
+			if err := writeGoToJS(&array, *r.SubType, &offset); err != nil {
+				return err
+			}
+			w.WriteInline(`%s(go, sp, %d, (go, sp) => %s)`, ptr.JS, *sp, array.String())
+			*sp += ptr.Size
+			break
+		}
 		f, ok := bind.BridgeFunc[r.SubType.ArgType][strings.ToLower(r.SubType.Type)]
 		if !ok {
 			return fmt.Errorf("invalid type of pointer %s", r.Type)
 		}
 		w.WriteInline(`%s(go, sp, %d, %s)`, ptr.JS, *sp, f.JS)
 		*sp += ptr.Size
+	case bind.ModeChan:
+		f, ok := bind.BridgeFunc[bind.ModeChan][strings.ToLower(r.SubType.Type)]
+		if !ok {
+			return fmt.Errorf("invalid type of channel %s", r.SubType.Type)
+		}
+		if r.Dir == "chan<-" {
+			return fmt.Errorf("invalid direction of channel %s, it must be able to receive", r.Dir)
+		}
+		padding(sp, f.Alignment())
+		w.WriteInline(`%s(go, sp, %d)`, f.JS, *sp)
+		*sp += f.Size
+	case bind.ModeMap:
+		m, ok := bind.BridgeFunc[bind.ModeMap][r.Type]
+		if !ok {
+			m = bind.BridgeFunc[bind.ModeMap]["default"]
+		}
+		k, ok := bind.BridgeFunc[bind.ModeArray][strings.ToLower(r.Key.Type)]
+		if !ok {
+			return fmt.Errorf("invalid type of map key %s", r.Key.Type)
+		}
+		v, ok := bind.BridgeFunc[bind.ModeArray][strings.ToLower(r.SubType.Type)]
+		if !ok {
+			return fmt.Errorf("invalid type of map value %s", r.SubType.Type)
+		}
+		padding(sp, 8)
+		w.WriteInline(`%s(go, sp, %d, %s, %s)`, m.JS, *sp, k.JS, v.JS)
+		*sp += m.Size
 	}
 
 	return nil
 }
 
+// writeBack writes the JS value v into the memory of the pointer
+// argument, at the offset sp, used by OptionOut and OptionInOut. Nil
+// pointers are ignored.
+func writeBack(w *writer, r bind.Argument, sp int, v string) error {
+	if r.ArgType != bind.ModePointer {
+		return fmt.Errorf("invalid type of %s, it must be a pointer", r.Type)
+	}
+	elem := r.SubType.Type
+	if r.SubType.ArgType == bind.ModeArray {
+		elem = r.SubType.SubType.Type
+	}
+	if strings.EqualFold(elem, "string") {
+		// Strings are given as Object, which isn't a string header.
+		return fmt.Errorf("invalid type of pointer %s, strings can't be written back", elem)
+	}
+
+	// The value is written at the pointer, with offset 0.
+	value, offset := writer{Buffer: bytes.NewBuffer(nil)}, 0
+	if err := writeJSToGo(&value, *r.SubType, &offset, v); err != nil {
+		return err
+	}
+	ptr, _ := bind.ResultFunc[bind.ModePointer]["default"]
+	w.Write(`%s(go, sp, %d, (go, sp) => %s)`, ptr.JS, sp, value.String())
+	return nil
+}
+
 func writeJSToGo(w *writer, r bind.Argument, sp *int, v string) error {
 	switch r.ArgType {
 	case bind.ModeStatic:
@@ -560,7 +1187,7 @@ func writeJSToGo(w *writer, r bind.Argument, sp *int, v string) error {
 		if !ok {
 			return fmt.Errorf("invalid type of %s", r.Type)
 		}
-		padding(sp, f.Size)
+		padding(sp, f.Alignment())
 		w.Write(`%s(go, sp, %d, %s)`, f.JS, *sp, v)
 		*sp += f.Size
 	case bind.ModeArray:
@@ -569,18 +1196,60 @@ func writeJSToGo(w *writer, r bind.Argument, sp *int, v string) error {
 		if !ok {
 			return fmt.Errorf("invalid type of slice %s", r.Type)
 		}
-		padding(sp, f.Size)
+		padding(sp, f.Alignment())
 		w.Write(`%s(go, sp, %d, %s, %d, %d, %s)`, array.JS, *sp, v, r.Len, f.Size, f.JS)
 		*sp += int(r.Len) * f.Size
+	case bind.ModePointer:
+		f, ok := bind.ResultFunc[bind.ModePointer][strings.ToLower(r.SubType.Type)]
+		if r.SubType.ArgType != bind.ModeStatic {
+			return fmt.Errorf("invalid type of pointer, pointers to arrays can't be results")
+		}
+		if !ok {
+			return fmt.Errorf("invalid type of pointer %s", r.SubType.Type)
+		}
+		padding(sp, f.Alignment())
+		w.Write(`%s(go, sp, %d, %s)`, f.JS, *sp, v)
+		*sp += f.Size
 	case bind.ModeSlice:
-		slice, _ := bind.ResultFunc[r.ArgType]["default"]
+		slice, ok := bind.ResultFunc[r.ArgType][strings.ToLower(r.SubType.Type)]
+		if !ok {
+			slice = bind.ResultFunc[r.ArgType]["default"]
+		}
 		f, ok := bind.BridgeFunc[bind.ModeArray][strings.ToLower(r.SubType.Type)]
 		if !ok {
 			return fmt.Errorf("invalid type of slice %s", r.Type)
 		}
+		if s, ok := bind.ResultFunc[bind.ModeStatic][strings.ToLower(r.SubType.Type)]; ok && s.Align > 0 {
+			// Exported structs, which have Align, can't be copied as bytes.
+			return fmt.Errorf("invalid type of slice %s, use an array of structs instead", r.SubType.Type)
+		}
 		padding(sp, slice.Size)
 		w.Write(`%s(go, sp, %d, %s, %d)`, slice.JS, *sp, v, f.Size)
 		*sp += slice.Size
+	case bind.ModeChan:
+		f, ok := bind.ResultFunc[bind.ModeChan][strings.ToLower(r.SubType.Type)]
+		if !ok {
+			return fmt.Errorf("invalid type of channel %s", r.SubType.Type)
+		}
+		if r.Dir != "<-chan" {
+			// Only inkwasm.ReceiveChan can close the channel.
+			return fmt.Errorf("invalid direction of channel %s, it must be <-chan", r.Dir)
+		}
+		padding(sp, f.Alignment())
+		w.Write(`%s(go, sp, %d, %s)`, f.JS, *sp, v)
+		*sp += f.Size
+	case bind.ModeMap:
+		// The keys and values are decoded as values of channels.
+		if _, ok := bind.ResultFunc[bind.ModeChan][strings.ToLower(r.Key.Type)]; !ok || strings.EqualFold(r.Key.Type, "inkwasm.object") {
+			return fmt.Errorf("invalid type of map key %s", r.Key.Type)
+		}
+		if _, ok := bind.ResultFunc[bind.ModeChan][strings.ToLower(r.SubType.Type)]; !ok {
+			return fmt.Errorf("invalid type of map value %s", r.SubType.Type)
+		}
+		f := bind.ResultFunc[bind.ModeMap]["default"]
+		padding(sp, f.Alignment())
+		w.Write(`%s(go, sp, %d, %s)`, f.JS, *sp, v)
+		*sp += f.Size
 	default:
 		return fmt.Errorf("unsupported result type of %s", r.Type)
 	}
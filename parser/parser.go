@@ -2,12 +2,16 @@ package parser
 
 import (
 	"errors"
+	"fmt"
 	"go/ast"
 	"go/token"
+	"go/types"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/inkeliz/go_inkwasm/bind"
 	"golang.org/x/tools/go/ast/astutil"
@@ -16,24 +20,34 @@ import (
 
 type Parser struct {
 	PackagesConfig *packages.Config
-	parsed         map[string][]*bind.Function
-	visited        map[string]bool
+	// Types is the package of the files given to ParseFile, it's used to
+	// resolve the named inkwasm.Object types, such as receivers. It's
+	// defined by ParsePackage.
+	Types    *types.Package
+	parsed   map[string][]*bind.Function
+	visited  map[string]bool
+	packages []bind.Package
+	modules  map[bind.Package]*packages.Module
 }
 
 func NewParser() *Parser {
 	return &Parser{
 		PackagesConfig: &packages.Config{
-			Mode:  packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedSyntax | packages.NeedTypes,
+			Mode:  packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedSyntax | packages.NeedTypes | packages.NeedModule,
 			Env:   append(os.Environ(), "GOOS=js", "GOARCH=wasm"),
 			Tests: true,
 		},
 		parsed:  make(map[string][]*bind.Function, 128),
 		visited: make(map[string]bool, 128),
+		modules: make(map[bind.Package]*packages.Module, 128),
 	}
 }
 
-func (p *Parser) ParsePackages(dir string) (map[bind.Package][]*bind.Function, error) {
-	pkgs, err := packages.Load(p.PackagesConfig, dir)
+// ParsePackages parses the given packages and their imports. Problems
+// found in directives are returned as bind.Diagnostics, along with all
+// functions without problems.
+func (p *Parser) ParsePackages(patterns ...string) (map[bind.Package][]*bind.Function, error) {
+	pkgs, err := packages.Load(p.PackagesConfig, patterns...)
 	if err != nil {
 		return nil, err
 	}
@@ -42,28 +56,37 @@ func (p *Parser) ParsePackages(dir string) (map[bind.Package][]*bind.Function, e
 		return nil, errors.New("no package found")
 	}
 
+	var diags bind.Diagnostics
 	m := make(map[bind.Package][]*bind.Function, 128)
 	for _, pkg := range pkgs {
 		if strings.Contains(pkg.PkgPath, ".test") {
 			info, err := p.ParsePackage(pkg)
 			if err != nil {
-				return nil, err
+				if _, ok := err.(bind.Diagnostics); !ok {
+					return nil, err
+				}
+				diags.Append(err)
+			}
+			if len(pkg.GoFiles) > 0 {
+				m[bind.Package{Name: pkg.Name, Path: pkg.PkgPath, Dir: filepath.Dir(pkg.GoFiles[0])}] = info
 			}
-			m[bind.Package{Name: pkg.Name, Path: pkg.PkgPath, Dir: filepath.Dir(pkg.GoFiles[0])}] = info
 		} else {
-			if err := p.parsePackages(m, pkg); err != nil {
+			if err := p.parsePackages(m, &diags, pkg); err != nil {
 				return nil, err
 			}
 		}
 	}
 
-	return m, nil
+	return m, diags.Err()
 }
 
-func (p *Parser) parsePackages(m map[bind.Package][]*bind.Function, pkg *packages.Package) error {
+func (p *Parser) parsePackages(m map[bind.Package][]*bind.Function, diags *bind.Diagnostics, pkg *packages.Package) error {
 	info, err := p.ParsePackage(pkg)
 	if err != nil {
-		return err
+		if _, ok := err.(bind.Diagnostics); !ok {
+			return err
+		}
+		diags.Append(err)
 	}
 	if len(info) > 0 {
 		m[bind.Package{Name: pkg.Name, Path: pkg.PkgPath, Dir: filepath.Dir(pkg.GoFiles[0])}] = info
@@ -72,7 +95,7 @@ func (p *Parser) parsePackages(m map[bind.Package][]*bind.Function, pkg *package
 	for _, imp := range pkg.Imports {
 		if !p.visited[imp.ID] {
 			p.visited[imp.ID] = true
-			if err := p.parsePackages(m, imp); err != nil {
+			if err := p.parsePackages(m, diags, imp); err != nil {
 				return err
 			}
 		}
@@ -81,25 +104,86 @@ func (p *Parser) parsePackages(m map[bind.Package][]*bind.Function, pkg *package
 	return nil
 }
 
+// Packages returns all packages parsed, including the ones without
+// any directive.
+func (p *Parser) Packages() []bind.Package {
+	return p.packages
+}
+
+// Module returns the module of the given package, it may be nil if the
+// package doesn't belong to any module.
+func (p *Parser) Module(pkg bind.Package) *packages.Module {
+	return p.modules[pkg]
+}
+
 func (p *Parser) ParsePackage(pkg *packages.Package) ([]*bind.Function, error) {
 	if len(pkg.GoFiles) == 0 {
 		return nil, nil
 	}
 
-	var infos []*bind.Function
+	b := bind.Package{Name: pkg.Name, Path: pkg.PkgPath, Dir: filepath.Dir(pkg.GoFiles[0])}
+	p.packages = append(p.packages, b)
+	p.modules[b] = pkg.Module
+	p.Types = pkg.Types
+
+	var (
+		infos []*bind.Function
+		diags bind.Diagnostics
+	)
 	for _, f := range pkg.Syntax {
 		b, err := p.ParseFile(pkg.PkgPath, pkg.Fset, f)
-		if err != nil {
-			return nil, err
-		}
+		diags.Append(err)
 		infos = append(infos, b...)
 	}
 
-	return infos, nil
+	return infos, diags.Err()
 }
 
+// ParseFile returns the functions of the given file. Functions with
+// problems are not returned, and the problems are returned as
+// bind.Diagnostics instead.
 func (p *Parser) ParseFile(pkg string, fset *token.FileSet, file *ast.File) (b []*bind.Function, err error) {
-	var info *bind.Function
+	var (
+		info  *bind.Function
+		diags bind.Diagnostics
+	)
+
+	position := func(pos token.Pos) {
+		if fset == nil {
+			return
+		}
+		info.File = fset.File(pos).Name()
+		info.IsTest = strings.Contains(info.File, "_test")
+		position := fset.PositionFor(pos, true)
+		info.Line, info.Column = position.Line, position.Column
+	}
+
+	// fail reports the problem of the current function, which is
+	// always the last one, and removes it.
+	fail := func(err error) {
+		d := bind.Diagnostic{
+			File:     info.File,
+			Line:     info.Line,
+			Column:   info.Column,
+			Severity: bind.SeverityError,
+			Code:     bind.CodeUnsupportedType,
+			Message:  err.Error(),
+		}
+		for _, err := range flatten(err) {
+			d := d
+			if err, ok := err.(parseError); ok {
+				d.Code, d.Message = err.code, err.message
+				if fset != nil && err.pos.IsValid() {
+					position := fset.PositionFor(err.pos, true)
+					d.Line, d.Column = position.Line, position.Column
+				}
+			}
+			diags = append(diags, d)
+		}
+		b = b[:len(b)-1]
+		info = nil
+	}
+
 	astutil.Apply(file, nil, func(c *astutil.Cursor) bool {
 		n := c.Node()
 		switch x := n.(type) {
@@ -107,10 +191,7 @@ func (p *Parser) ParseFile(pkg string, fset *token.FileSet, file *ast.File) (b [
 			if info != nil {
 				return true
 			}
-			info, err = p.parseComment(x.Text)
-			if err != nil {
-				return false
-			}
+			info = p.parseComment(x.Text)
 			if info != nil {
 				b = append(b, info)
 			}
@@ -118,89 +199,289 @@ func (p *Parser) ParseFile(pkg string, fset *token.FileSet, file *ast.File) (b [
 			if info == nil {
 				return true
 			}
+			position(x.Pos())
+			if info.Hint == bind.HintBind {
+				fail(parseError{pos: x.Pos(), code: bind.CodeInvalidBind, message: fmt.Sprintf("invalid usage of %s on function %s, it must be used on interfaces", info.Hint, x.Name.Name)})
+				return true
+			}
 			if info.FunctionGolang, err = p.parseFunction(pkg, x); err != nil {
-				return false
+				fail(err)
+				return true
 			}
-			if fset != nil {
-				info.File = fset.File(x.Pos()).Name()
-				info.IsTest = strings.Contains(info.File, "_test")
-				info.Line = fset.PositionFor(x.Pos(), true).Line
+			if x.Body != nil {
+				fail(parseError{pos: x.Body.Pos(), code: bind.CodeDirectiveBody, message: fmt.Sprintf("function %s must not have a body, it's implemented by the generated assembly", x.Name.Name)})
+				return true
 			}
 			info = nil
 		case *ast.TypeSpec:
 			if info == nil {
 				return true
 			}
+			if info.Hint == bind.HintBind {
+				position(x.Pos())
+				methods, err := p.parseInterface(pkg, info, fset, x)
+				if err != nil {
+					fail(err)
+					return true
+				}
+				b = append(b[:len(b)-1], methods...)
+				info = nil
+				return true
+			}
 			info.FunctionGolang.Name = x.Name.Name
+			if info.Hint == bind.HintExport {
+				if _, ok := x.Type.(*ast.StructType); !ok {
+					position(x.Pos())
+					fail(parseError{pos: x.Type.Pos(), code: bind.CodeInvalidStruct, message: fmt.Sprintf("invalid usage of %s on type %s, it must be used on structs", info.Hint, x.Name.Name)})
+					return true
+				}
+				if err := p.structLayout(info, x.Name.Name); err != nil {
+					fail(parseError{pos: x.Pos(), code: bind.CodeInvalidStruct, message: err.Error()})
+					return true
+				}
+			}
 			info = nil
 		case *ast.StructType:
-			if info == nil {
+			if info == nil || info.Hint == bind.HintBind {
 				return true
 			}
+			position(x.Pos())
 			if info.FunctionGolang, err = p.parseStruct(pkg, x); err != nil {
-				return false
-			}
-			if fset != nil {
-				info.File = fset.File(x.Pos()).Name()
-				info.IsTest = strings.Contains(info.File, "_test")
-				info.Line = fset.PositionFor(x.Pos(), true).Line
-			}
-		default:
-			if info != nil {
-				//	fmt.Printf("%T %v \n", x, x)
+				fail(err)
+				return true
 			}
 		}
 
 		return true
 	})
 
-	return b, err
+	return b, diags.Err()
+}
+
+// flatten returns all errors joined by errors.Join.
+func flatten(err error) []error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var errs []error
+	for _, err := range joined.Unwrap() {
+		errs = append(errs, flatten(err)...)
+	}
+	return errs
+}
+
+// parseError is an error in the declaration, at the given position.
+type parseError struct {
+	pos     token.Pos
+	code    bind.Code
+	message string
 }
 
-type filter func(b byte) (filter, error)
+func (e parseError) Error() string {
+	return e.message
+}
 
-func (p *Parser) parseComment(s string) (b *bind.Function, err error) {
-	const prefixLen = len("//inkwasm:")
+func (p *Parser) parseComment(s string) (b *bind.Function) {
+	const prefix = "//inkwasm:"
 
-	if len(s) <= prefixLen || !strings.HasPrefix(s, "//inkwasm:") {
-		return b, nil
+	if len(s) <= len(prefix) || !strings.HasPrefix(s, prefix) {
+		return b
 	}
 
-	var jsName, jsHint strings.Builder
-	var hint, function filter
+	hint, rest := s[len(prefix):], ""
+	if i := strings.IndexAny(hint, " \t"); i >= 0 {
+		hint, rest = hint[:i], hint[i+1:]
+	}
 
-	hint = func(b byte) (filter, error) {
-		switch b {
-		case ' ', '\t':
-			return function, nil
-		default:
-			jsHint.WriteByte(b)
-			return hint, nil
+	b = &bind.Function{
+		FunctionJavascript: bind.FunctionJavascript{
+			Hint: bind.Hint(hint),
+		},
+	}
+
+	// The first field is the name, the others are options.
+	for i, field := range splitFields(rest) {
+		if i == 0 {
+			b.FunctionJavascript.Name = field
+			continue
+		}
+		if b.FunctionJavascript.Options == nil {
+			b.FunctionJavascript.Options = make(bind.Options)
+		}
+		k, v, _ := strings.Cut(field, "=")
+		b.FunctionJavascript.Options[k] = v
+	}
+
+	return b
+}
+
+// splitFields splits s around spaces, except the ones inside of
+// brackets, parentheses, braces or quotes, such as "a(b, c) d".
+func splitFields(s string) (fields []string) {
+	var (
+		depth int
+		quote byte
+		start = -1
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case (c == ' ' || c == '\t') && depth <= 0:
+			if start >= 0 {
+				fields = append(fields, s[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
 		}
 	}
-	function = func(b byte) (filter, error) {
-		jsName.WriteByte(b)
-		return function, nil
+	if start >= 0 {
+		fields = append(fields, s[start:])
 	}
+	return fields
+}
 
-	i := prefixLen
-	l := len(s)
-	for f := hint; f != nil; {
-		if i >= l {
-			break
+// parseInterface returns the methods of the interface, given to
+// HintBind, which are implemented by the generated "<Interface>JS".
+//
+// Each method uses the member of the target with the same name, in
+// lowerCamelCase, which can be changed with tags in the comment of the
+// method, such as:
+//
+//	Length() int // js:"length" hint:"get"
+func (p *Parser) parseInterface(pkg string, info *bind.Function, fset *token.FileSet, spec *ast.TypeSpec) ([]*bind.Function, error) {
+	iface, ok := spec.Type.(*ast.InterfaceType)
+	if !ok {
+		return nil, parseError{pos: spec.Pos(), code: bind.CodeInvalidBind, message: fmt.Sprintf("invalid usage of %s on %s, it must be used on interfaces", info.Hint, spec.Name.Name)}
+	}
+
+	target := info.FunctionJavascript.Name
+	switch {
+	case target == "":
+		return nil, parseError{pos: spec.Pos(), code: bind.CodeInvalidBind, message: fmt.Sprintf("missing target of %s, such as //inkwasm:bind globalThis.localStorage", spec.Name.Name)}
+	case IsTemplate(target):
+		return nil, parseError{pos: spec.Pos(), code: bind.CodeInvalidBind, message: fmt.Sprintf("invalid target of %s, templates aren't supported (%s)", spec.Name.Name, target)}
+	case spec.TypeParams != nil:
+		return nil, parseError{pos: spec.Pos(), code: bind.CodeInvalidBind, message: fmt.Sprintf("invalid usage of %s on %s, generic interfaces aren't supported", info.Hint, spec.Name.Name)}
+	case len(iface.Methods.List) == 0:
+		return nil, parseError{pos: spec.Pos(), code: bind.CodeInvalidBind, message: fmt.Sprintf("interface %s has no methods", spec.Name.Name)}
+	}
+	if target == "." {
+		target = ""
+	}
+
+	name := spec.Name.Name + "JS"
+
+	var (
+		methods []*bind.Function
+		errs    []error
+	)
+	for _, field := range iface.Methods.List {
+		f, ok := field.Type.(*ast.FuncType)
+		if !ok || len(field.Names) == 0 {
+			errs = append(errs, parseError{pos: field.Pos(), code: bind.CodeInvalidBind, message: fmt.Sprintf("embedded interfaces aren't supported, declare the methods of %s", types.ExprString(field.Type))})
+			continue
 		}
-		if f, err = f(s[i]); err != nil {
-			return nil, err
+
+		method := &bind.Function{
+			File:   info.File,
+			IsTest: info.IsTest,
+			FunctionGolang: bind.FunctionGolang{
+				Name:      field.Names[0].Name,
+				Interface: spec.Name.Name,
+				Type:      name,
+			},
+			FunctionJavascript: bind.FunctionJavascript{
+				Name:    target + "." + lowerCamelCase(field.Names[0].Name),
+				Hint:    bind.HintFunc,
+				Options: info.FunctionJavascript.Options,
+			},
 		}
-		i++
+		if fset != nil {
+			position := fset.PositionFor(field.Pos(), true)
+			method.Line, method.Column = position.Line, position.Column
+		}
+		if strings.HasPrefix(target, ".") || target == "" {
+			method.Receiver = &bind.Argument{Name: "recv", ArgType: bind.ModeStatic, Type: "inkwasm.Object", Wrapper: name, Embedded: true}
+		}
+
+		tag := methodTag(field)
+		if js, ok := tag.Lookup("js"); ok && js != "" {
+			method.FunctionJavascript.Name = target + "." + js
+		}
+		if hint, ok := tag.Lookup("hint"); ok {
+			switch h := bind.Hint(hint); h {
+			case bind.HintFunc, bind.HintGet, bind.HintSet:
+				method.FunctionJavascript.Hint = h
+			default:
+				errs = append(errs, parseError{pos: field.Pos(), code: bind.CodeInvalidBind, message: fmt.Sprintf("invalid hint of %s, it must be 'func', 'get' or 'set' (%s)", method.FunctionGolang.Name, hint)})
+				continue
+			}
+		}
+
+		if err := errors.Join(
+			p.parseFields(pkg, &method.FunctionGolang.Arguments, f.Params),
+			p.parseFields(pkg, &method.FunctionGolang.Result, f.Results),
+		); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		methods = append(methods, method)
 	}
 
-	return &bind.Function{
-		FunctionJavascript: bind.FunctionJavascript{
-			Name: strings.TrimSpace(jsName.String()),
-			Hint: bind.Hint(jsHint.String()),
-		},
-	}, nil
+	return methods, errors.Join(errs...)
+}
+
+// methodTag returns the tag in the comments of the method, which uses
+// the same format of the tags of struct fields.
+func methodTag(field *ast.Field) reflect.StructTag {
+	for _, group := range []*ast.CommentGroup{field.Comment, field.Doc} {
+		if group == nil {
+			continue
+		}
+		for _, c := range group.List {
+			tag := reflect.StructTag(strings.TrimSpace(strings.TrimPrefix(c.Text, "//")))
+			if _, ok := tag.Lookup("js"); ok {
+				return tag
+			}
+			if _, ok := tag.Lookup("hint"); ok {
+				return tag
+			}
+		}
+	}
+	return ""
+}
+
+// lowerCamelCase returns the name with the leading uppercase letters
+// in lowercase, such as "getItem" for "GetItem" and "htmlElement" for
+// "HTMLElement".
+func lowerCamelCase(name string) string {
+	r := []rune(name)
+	for i := range r {
+		if !unicode.IsUpper(r[i]) {
+			break
+		}
+		if i > 0 && i+1 < len(r) && unicode.IsLower(r[i+1]) {
+			break
+		}
+		r[i] = unicode.ToLower(r[i])
+	}
+	return string(r)
 }
 
 func (p *Parser) parseFunction(pkg string, f *ast.FuncDecl) (bind.FunctionGolang, error) {
@@ -210,16 +491,100 @@ func (p *Parser) parseFunction(pkg string, f *ast.FuncDecl) (bind.FunctionGolang
 		Result:    nil,
 	}
 
-	if err := p.parseFields(pkg, &b.Arguments, f.Type.Params); err != nil {
-		return b, err
+	return b, errors.Join(
+		p.parseReceiver(&b, f.Recv),
+		p.parseFields(pkg, &b.Arguments, f.Type.Params),
+		p.parseFields(pkg, &b.Result, f.Type.Results),
+	)
+}
+
+func (p *Parser) parseReceiver(b *bind.FunctionGolang, recv *ast.FieldList) error {
+	if recv == nil || len(recv.List) == 0 {
+		return nil
 	}
 
-	if err := p.parseFields(pkg, &b.Result, f.Type.Results); err != nil {
-		return b, err
+	field := recv.List[0]
+	name := "recv"
+	if len(field.Names) > 0 && field.Names[0].Name != "_" {
+		name = field.Names[0].Name
 	}
-	return b, nil
+
+	switch t := field.Type.(type) {
+	case *ast.StarExpr:
+		return parseError{pos: field.Type.Pos(), code: bind.CodeInvalidReceiver, message: fmt.Sprintf("pointer receivers aren't supported, use %s instead of %s", types.ExprString(t.X), types.ExprString(t))}
+	case *ast.Ident:
+		b.Receiver = &bind.Argument{Name: name, ArgType: bind.ModeStatic, Type: "inkwasm.Object"}
+		if p.parseWrapper(b.Receiver, t.Name) && b.Receiver.Wrapper != "" {
+			return nil
+		}
+	}
+
+	b.Receiver = nil
+	return parseError{pos: field.Type.Pos(), code: bind.CodeInvalidReceiver, message: fmt.Sprintf("receiver %s must be a named inkwasm.Object, such as `type %[1]s inkwasm.Object` or `type %[1]s struct{ inkwasm.Object }`", types.ExprString(field.Type))}
+}
+
+// parseWrapper defines the Wrapper, if the given type is a named
+// inkwasm.Object, declared in the Types package. It returns false if
+// the type isn't an inkwasm.Object.
+func (p *Parser) parseWrapper(arg *bind.Argument, name string) bool {
+	if p.Types == nil {
+		return false
+	}
+	obj, ok := p.Types.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return false
+	}
+	object := p.objectType()
+	if object == nil {
+		return false
+	}
+
+	if types.Identical(obj.Type(), object) {
+		// Alias, or the inkwasm.Object itself.
+		arg.Type = "inkwasm.Object"
+		return true
+	}
+
+	switch t := obj.Type().Underlying().(type) {
+	case *types.Struct:
+		if types.Identical(t, object.Underlying()) {
+			arg.Type, arg.Wrapper = "inkwasm.Object", name
+			return true
+		}
+		for i := 0; i < t.NumFields(); i++ {
+			if f := t.Field(i); f.Embedded() && types.Identical(f.Type(), object) {
+				arg.Type, arg.Wrapper, arg.Embedded = "inkwasm.Object", name, true
+				return true
+			}
+		}
+	}
+	return false
 }
 
+// objectType returns the inkwasm.Object, if the Types package is
+// the inkwasm package or imports it.
+func (p *Parser) objectType() types.Type {
+	pkg := p.Types
+	if pkg.Path() != inkwasmPath {
+		pkg = nil
+		for _, imp := range p.Types.Imports() {
+			if imp.Path() == inkwasmPath {
+				pkg = imp
+			}
+		}
+	}
+	if pkg == nil {
+		return nil
+	}
+	obj, ok := pkg.Scope().Lookup("Object").(*types.TypeName)
+	if !ok {
+		return nil
+	}
+	return obj.Type()
+}
+
+const inkwasmPath = "github.com/inkeliz/go_inkwasm/inkwasm"
+
 func (p *Parser) parseStruct(pkg string, f *ast.StructType) (bind.FunctionGolang, error) {
 	b := bind.FunctionGolang{
 		Arguments: nil,
@@ -229,61 +594,159 @@ func (p *Parser) parseStruct(pkg string, f *ast.StructType) (bind.FunctionGolang
 		return b, err
 	}
 
-	if len(b.Arguments) > 0 && (b.Arguments[0].Name != "_" || b.Arguments[0].Type != "uint64") {
-		return b, errors.New(`first argument of exported struct must be uint64 and named as "_"`)
+	// Embedded fields are named as the type.
+	i := 0
+	for _, field := range f.Fields.List {
+		if len(field.Names) > 0 {
+			i += len(field.Names)
+			continue
+		}
+		if i < len(b.Arguments) {
+			b.Arguments[i].Name = embeddedName(field.Type)
+		}
+		i++
 	}
 
 	return b, nil
 }
 
+func embeddedName(t ast.Expr) string {
+	switch t := t.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	default:
+		return "_"
+	}
+}
+
+// structLayout defines the offsets of the fields, the size and the
+// alignment of the exported struct, using GOARCH=wasm. It's a no-op
+// without the Types, then the offsets are computed by the Binder.
+func (p *Parser) structLayout(info *bind.Function, name string) error {
+	if p.Types == nil {
+		return nil
+	}
+	obj := p.Types.Scope().Lookup(name)
+	if obj == nil {
+		return nil
+	}
+	st, ok := obj.Type().Underlying().(*types.Struct)
+	if !ok {
+		return fmt.Errorf("invalid layout of %s, it isn't a struct", name)
+	}
+	if st.NumFields() != len(info.Arguments) {
+		return fmt.Errorf("invalid layout of %s, it has %d fields, but %d are declared", name, st.NumFields(), len(info.Arguments))
+	}
+	for i := range info.Arguments {
+		if f := st.Field(i); f.Name() != info.Arguments[i].Name {
+			return fmt.Errorf("invalid layout of %s, the field %s doesn't match %s", name, info.Arguments[i].Name, f.Name())
+		}
+	}
+
+	fields := make([]*types.Var, st.NumFields())
+	for i := range fields {
+		fields[i] = st.Field(i)
+	}
+
+	sizes := types.SizesFor("gc", "wasm")
+	for i, offset := range sizes.Offsetsof(fields) {
+		info.Arguments[i].Offset = offset
+	}
+	info.Size, info.Align = sizes.Sizeof(st), sizes.Alignof(st)
+	return nil
+}
+
+// parseFields parses all fields, the returned error includes the
+// problems of all fields.
 func (p *Parser) parseFields(pkg string, out *[]bind.Argument, fields *ast.FieldList) error {
 	if fields == nil || len(fields.List) == 0 {
 		return nil
 	}
 
+	var errs []error
+	parser := p
 	for _, p := range fields.List {
-		if len(p.Names) == 0 {
-			p.Names = []*ast.Ident{{Name: "_"}}
+		// The AST is not modified, since it may be shared, such as
+		// by go/analysis.
+		names := p.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{{Name: "_"}}
 		}
-		for _, n := range p.Names {
+		for _, n := range names {
 			*out = append(*out, bind.Argument{Name: n.Name})
 			arg := &((*out)[len(*out)-1])
 
 			if p.Tag != nil {
-				i := strings.Index(p.Tag.Value, "js:")
-				if i > -1 || len(p.Tag.Value) > len("js:")+1 {
-					s := p.Tag.Value[i+len("js:")+1:]
-					if i = strings.Index(s, `"`); i > -1 {
-						arg.Tag = s[:i]
-					}
-				}
+				parseTag(arg, p.Tag)
 			}
 
+			var err error
 			switch t := p.Type.(type) {
 			case *ast.ArrayType:
-				if err := parseArray(arg, t); err != nil {
-					return err
-				}
+				err = parseArray(arg, t)
 			case *ast.Ident:
+				err = parseIdent(arg, t)
 				if t.Name == "Object" && pkg == "github.com/inkeliz/go_inkwasm/inkwasm" {
-					t.Name = "inkwasm." + t.Name
-				}
-				if err := parseIdent(arg, t); err != nil {
-					return err
+					arg.Type = "inkwasm." + t.Name
+				} else {
+					parser.parseWrapper(arg, t.Name)
 				}
 			case *ast.SelectorExpr:
-				if err := parseSelector(arg, t); err != nil {
-					return err
-				}
+				err = parseSelector(arg, t)
 			case *ast.StarExpr:
-				if err := parsePointer(arg, t); err != nil {
-					return err
+				err = parsePointer(arg, t)
+			case *ast.Ellipsis:
+				err = parseEllipsis(arg, t)
+			case *ast.ChanType:
+				err = parseChan(arg, t)
+			case *ast.MapType:
+				err = parseMap(arg, t)
+			case *ast.InterfaceType:
+				arg.ArgType = bind.ModeStatic
+				arg.Type = "interface{}"
+			default:
+				err = fmt.Errorf("unsupported type %s", types.ExprString(p.Type))
+			}
+			if err != nil {
+				errs = append(errs, parseError{pos: p.Type.Pos(), code: bind.CodeUnsupportedType, message: err.Error()})
+				break
+			}
+			for _, sub := range []*bind.Argument{arg.SubType, arg.Key} {
+				if sub != nil && sub.Type == "Object" && pkg == inkwasmPath {
+					sub.Type = "inkwasm.Object"
 				}
 			}
 		}
 	}
 
-	return nil
+	return errors.Join(errs...)
+}
+
+// parseTag parses the js tag, such as `js:"name,omitempty"`. Like
+// encoding/json, "-" omits the field and "-," is the name "-".
+func parseTag(arg *bind.Argument, tag *ast.BasicLit) {
+	s, err := strconv.Unquote(tag.Value)
+	if err != nil {
+		return
+	}
+	name, options, found := strings.Cut(reflect.StructTag(s).Get("js"), ",")
+	if name == "-" && !found {
+		arg.Omit = true
+		return
+	}
+	arg.Tag = name
+	for _, o := range strings.Split(options, ",") {
+		switch o {
+		case "omitempty":
+			arg.OmitEmpty = true
+		case "inline":
+			arg.Inline = true
+		}
+	}
 }
 
 func parseIdent(arg *bind.Argument, t *ast.Ident) error {
@@ -300,6 +763,11 @@ func parsePointer(arg *bind.Argument, t *ast.StarExpr) error {
 		return parseIdent(arg.SubType, tt)
 	case *ast.SelectorExpr:
 		return parseSelector(arg.SubType, tt)
+	case *ast.ArrayType:
+		if tt.Len == nil {
+			return errors.New("pointer to slice isn't supported")
+		}
+		return parseArray(arg.SubType, tt)
 	default:
 		return errors.New("invalid format")
 	}
@@ -318,6 +786,63 @@ func parseSelector(arg *bind.Argument, t *ast.SelectorExpr) error {
 	}
 }
 
+// parseEllipsis parses the variadic argument, which is a slice
+// spread into the arguments of the JS function.
+func parseEllipsis(arg *bind.Argument, t *ast.Ellipsis) error {
+	arg.Variadic = true
+	return parseArray(arg, &ast.ArrayType{Elt: t.Elt})
+}
+
+// parseChan parses the channel, which is an async iterable on the
+// JS side.
+func parseChan(arg *bind.Argument, t *ast.ChanType) error {
+	arg.ArgType = bind.ModeChan
+	switch t.Dir {
+	case ast.SEND:
+		arg.Dir = "chan<-"
+	case ast.RECV:
+		arg.Dir = "<-chan"
+	default:
+		arg.Dir = "chan"
+	}
+	arg.SubType = new(bind.Argument)
+	switch tt := t.Value.(type) {
+	case *ast.Ident:
+		return parseIdent(arg.SubType, tt)
+	case *ast.SelectorExpr:
+		return parseSelector(arg.SubType, tt)
+	default:
+		return errors.New("channel of composite types isn't supported")
+	}
+}
+
+// parseMap parses the map, which is a JS object, or Map.
+func parseMap(arg *bind.Argument, t *ast.MapType) error {
+	arg.ArgType = bind.ModeMap
+	arg.Key, arg.SubType = new(bind.Argument), new(bind.Argument)
+	for _, v := range []struct {
+		arg  *bind.Argument
+		expr ast.Expr
+	}{{arg.Key, t.Key}, {arg.SubType, t.Value}} {
+		var err error
+		switch tt := v.expr.(type) {
+		case *ast.Ident:
+			err = parseIdent(v.arg, tt)
+		case *ast.SelectorExpr:
+			err = parseSelector(v.arg, tt)
+		case *ast.InterfaceType:
+			v.arg.ArgType = bind.ModeStatic
+			v.arg.Type = "interface{}"
+		default:
+			err = errors.New("map of composite types isn't supported")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func parseArray(arg *bind.Argument, t *ast.ArrayType) error {
 	if t.Len == nil {
 		arg.ArgType = bind.ModeSlice
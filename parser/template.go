@@ -0,0 +1,35 @@
+package parser
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// placeholder matches the arguments of templates, such as "$0", and
+// the receiver of methods, "$this".
+var placeholder = regexp.MustCompile(`\$(?:[0-9]+|this)`)
+
+// IsTemplate reports whether the name is a template, which uses the
+// arguments as placeholders, such as "globalThis.gl[$0].uniform1f($1)".
+func IsTemplate(name string) bool {
+	return placeholder.MatchString(name)
+}
+
+// templateArguments returns the index of the arguments used by the
+// template, in the order they appear, which may repeat. The receiver
+// is reported by this.
+func templateArguments(name string) (indexes []int, this bool) {
+	for _, m := range placeholder.FindAllString(name, -1) {
+		if m == "$this" {
+			this = true
+			continue
+		}
+		i, err := strconv.Atoi(m[1:])
+		if err != nil {
+			// Too large to be a valid index.
+			i = -1
+		}
+		indexes = append(indexes, i)
+	}
+	return indexes, this
+}
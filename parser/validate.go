@@ -0,0 +1,221 @@
+package parser
+
+import (
+	"bytes"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/inkeliz/go_inkwasm/bind"
+)
+
+// Validate returns the problems of the given function, as bind.Diagnostics,
+// using the same rules of the Binder. Exported structs are validated when
+// created, since they may use other exported structs.
+//
+// The Binder registers the exported structs before validating, others
+// must provide the names of the exported structs of the package, which
+// are assumed valid.
+func Validate(info *bind.Function, exported ...string) error {
+	var diags bind.Diagnostics
+
+	if info.FunctionJavascript.Hint == bind.HintExport {
+		return nil
+	}
+
+	args := info.FunctionGolang.Arguments
+	template := IsTemplate(info.FunctionJavascript.Name)
+	if template {
+		diags = append(diags, validateTemplate(info)...)
+	} else if info.FunctionGolang.Receiver != nil {
+		// The receiver is the JS this, func uses Function.call.
+		if info.FunctionJavascript.Hint != bind.HintFunc && !strings.HasPrefix(info.FunctionJavascript.Name, ".") {
+			diags.Append(info.CreateError(bind.CodeInvalidReceiver, "invalid usage of %s with receiver, the name must start with dot or be a template using $this (%s)", info.FunctionJavascript.Hint, info.FunctionJavascript.Name))
+		}
+	} else if strings.HasPrefix(info.FunctionJavascript.Name, ".") {
+		if len(args) == 0 || !strings.EqualFold(args[0].Type, "inkwasm.object") {
+			diags.Append(info.CreateError(bind.CodeMissingReceiver, "the first argument must be inkwasm.Object, since it starts with dot (%s)", info.FunctionJavascript.Name))
+		} else {
+			args = args[1:]
+		}
+	}
+
+	// Variadic arguments are spread into the call.
+	if len(args) > 0 && args[len(args)-1].Variadic && !template {
+		if h := info.FunctionJavascript.Hint; h == bind.HintGet || h == bind.HintSet {
+			diags.Append(info.CreateError(bind.CodeInvalidArguments, "invalid usage of %s. Variadic arguments are only supported by func and new.", h))
+		}
+	}
+
+	switch info.FunctionJavascript.Hint {
+	case bind.HintGet:
+		if len(args) > 1 && !template {
+			diags.Append(info.CreateError(bind.CodeInvalidArguments, "invalid usage of %s. Function can't have more than one argument", info.FunctionJavascript.Hint))
+		}
+	case bind.HintFunc, bind.HintNew:
+	case bind.HintSet:
+		if template && len(args) == 0 {
+			diags.Append(info.CreateError(bind.CodeInvalidArguments, "invalid usage of %s. Function must have at least one argument, the last one is the value.", info.FunctionJavascript.Hint))
+		}
+		if len(args) != 1 && !template {
+			diags.Append(info.CreateError(bind.CodeInvalidArguments, "invalid usage of %s. Function must have one argument.", info.FunctionJavascript.Hint))
+		}
+		if len(info.Result) > 0 {
+			diags.Append(info.CreateError(bind.CodeInvalidResults, "invalid usage of %s. Set can't have results.", info.FunctionJavascript.Hint))
+		}
+	default:
+		diags.Append(info.CreateError(bind.CodeUnknownHint, "unknown hint of '%s'. The comment must be //inkwasm:{hint}, where {hint} can be either 'func', 'get', 'set', 'new'.", info.FunctionJavascript.Hint))
+	}
+
+	diags = append(diags, validateOptions(info)...)
+
+	if len(info.Result) > 2 {
+		diags.Append(info.CreateError(bind.CodeInvalidResults, "function can only have a maximum of 2 results, currently having %d.", len(info.Result)))
+	}
+	if len(info.Result) == 2 && info.Result[1].Type != "bool" {
+		diags.Append(info.CreateError(bind.CodeInvalidResults, "when two results is provided, the last result of the function must be 'bool'"))
+	}
+
+	// The types are validated writing them into a discarded buffer, so
+	// the rules are the same of the generated code.
+	w, sp := writer{Buffer: bytes.NewBuffer(nil)}, 0
+	for _, r := range args {
+		if isExported(r, exported) {
+			continue
+		}
+		if err := writeGoToJS(&w, r, &sp); err != nil {
+			diags.Append(info.CreateError(bind.CodeUnsupportedType, "argument %s: %s", r.Name, err.Error()))
+		}
+	}
+	if len(info.Result) > 0 && !isExported(info.Result[0], exported) {
+		if err := writeJSToGo(&w, info.Result[0], &sp, "r"); err != nil {
+			diags.Append(info.CreateError(bind.CodeUnsupportedType, "result: %s", err.Error()))
+		}
+	}
+
+	// The out and inout arguments are written back after the call.
+	names := info.FunctionJavascript.Options.List(bind.OptionInOut)
+	if out := info.FunctionJavascript.Options[bind.OptionOut]; out != "" {
+		names = append(names, out)
+	}
+	for _, name := range names {
+		i := slices.IndexFunc(args, func(r bind.Argument) bool { return r.Name == name })
+		switch {
+		case i < 0:
+			diags.Append(info.CreateError(bind.CodeInvalidOption, "unknown argument %s, given by out or inout", name))
+		case isExported(args[i], exported):
+		default:
+			if err := writeBack(&w, args[i], 0, "r"); err != nil {
+				diags.Append(info.CreateError(bind.CodeUnsupportedType, "argument %s: %s", name, err.Error()))
+			}
+		}
+	}
+
+	return diags.Err()
+}
+
+func isExported(r bind.Argument, exported []string) bool {
+	if r.SubType != nil {
+		r = *r.SubType
+	}
+	for _, name := range exported {
+		if strings.EqualFold(r.Type, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateOptions(info *bind.Function) (diags bind.Diagnostics) {
+	pair := len(info.Result) == 2 && info.Result[1].Type == "bool"
+	for name, value := range info.FunctionJavascript.Options {
+		switch name {
+		case bind.OptionCatch:
+			switch value {
+			case "log", "silent":
+				if len(info.Result) > 0 && !pair {
+					diags.Append(info.CreateError(bind.CodeInvalidOption, "catch=%s requires no results, or the last result to be bool", value))
+				}
+			case "throw":
+			default:
+				diags.Append(info.CreateError(bind.CodeInvalidOption, "invalid value of catch (%q), it must be either 'log', 'silent' or 'throw'", value))
+			}
+		case bind.OptionCopy:
+			if value != "args" {
+				diags.Append(info.CreateError(bind.CodeInvalidOption, "invalid value of copy (%q), it must be 'args'", value))
+			}
+		case bind.OptionNullable:
+			if value != "" {
+				diags.Append(info.CreateError(bind.CodeInvalidOption, "nullable doesn't have value"))
+			}
+			if !pair {
+				diags.Append(info.CreateError(bind.CodeInvalidOption, "nullable requires two results, the last one must be bool"))
+			}
+		case bind.OptionComplex:
+			if value != "object" && value != "interleaved" {
+				diags.Append(info.CreateError(bind.CodeInvalidOption, "invalid value of complex (%q), it must be either 'object' or 'interleaved'", value))
+			}
+		case bind.OptionMap:
+			if value != "object" && value != "map" {
+				diags.Append(info.CreateError(bind.CodeInvalidOption, "invalid value of map (%q), it must be either 'object' or 'map'", value))
+			}
+		case bind.OptionOut:
+			if value == "" || strings.Contains(value, ",") {
+				diags.Append(info.CreateError(bind.CodeInvalidOption, "invalid value of out (%q), it must be the name of one argument", value))
+			}
+			if len(info.Result) > 0 {
+				diags.Append(info.CreateError(bind.CodeInvalidOption, "out requires no results, the result is written into %s", value))
+			}
+			if info.FunctionJavascript.Hint == bind.HintSet {
+				diags.Append(info.CreateError(bind.CodeInvalidOption, "out can't be used with set, which has no result"))
+			}
+			if slices.Contains(info.FunctionJavascript.Options.List(bind.OptionInOut), value) {
+				diags.Append(info.CreateError(bind.CodeInvalidOption, "argument %s can't be both out and inout", value))
+			}
+		case bind.OptionInOut:
+			if value == "" {
+				diags.Append(info.CreateError(bind.CodeInvalidOption, "invalid value of inout, it must be the names of the arguments, separated by comma"))
+			}
+		default:
+			diags.Append(info.CreateError(bind.CodeInvalidOption, "unknown option %q, it must be either 'catch', 'copy', 'nullable', 'complex', 'map', 'out' or 'inout'", name))
+		}
+	}
+	sort.Slice(diags, func(i, j int) bool { return diags[i].Message < diags[j].Message })
+	return diags
+}
+
+// validateTemplate checks if all placeholders are valid arguments, and
+// all arguments are used, except the value of set.
+func validateTemplate(info *bind.Function) (diags bind.Diagnostics) {
+	name := info.FunctionJavascript.Name
+	if strings.HasPrefix(name, ".") {
+		diags.Append(info.CreateError(bind.CodeInvalidTemplate, "template can't start with dot (%s), use $0 as the object", name))
+	}
+
+	n := len(info.FunctionGolang.Arguments)
+	used := make([]bool, n)
+	indexes, this := templateArguments(name)
+	if this && info.FunctionGolang.Receiver == nil {
+		diags.Append(info.CreateError(bind.CodeInvalidTemplate, "invalid placeholder in %s, $this requires a receiver", name))
+	}
+	for _, i := range indexes {
+		if i < 0 || i >= n {
+			if n == 0 {
+				diags.Append(info.CreateError(bind.CodeInvalidTemplate, "invalid placeholder in %s, the function has no arguments", name))
+			} else {
+				diags.Append(info.CreateError(bind.CodeInvalidTemplate, "invalid placeholder in %s, the function has %d arguments ($0 to $%d)", name, n, n-1))
+			}
+			continue
+		}
+		used[i] = true
+	}
+	if info.FunctionJavascript.Hint == bind.HintSet && n > 0 {
+		used[n-1] = true
+	}
+	for i, ok := range used {
+		if !ok && info.FunctionGolang.Arguments[i].Name != info.FunctionJavascript.Options[bind.OptionOut] {
+			diags.Append(info.CreateError(bind.CodeInvalidTemplate, "argument %s isn't used, the template %s doesn't have $%d", info.FunctionGolang.Arguments[i].Name, name, i))
+		}
+	}
+	return diags
+}